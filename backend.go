@@ -0,0 +1,73 @@
+package gphoto2go
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// Backend is the set of file, capture, config and event operations
+// offered by a camera.
+// It is implemented by *Camera and by *Fake, a camera that allows
+// testing code without any hardware attached (it still needs libgphoto2).
+type Backend interface {
+	ListFolders(folder string) ([]string, error)
+	RListFolders(folder string) ([]string, error)
+	ListFiles(folder string) ([]string, error)
+	Info(folder, file string) (*Info, error)
+	FileReader(folder, file string) io.ReadCloser
+	DeleteFile(folder, file string) error
+
+	TriggerCapture() error
+	TriggerCaptureToFile() (CameraFilePath, error)
+	CapturePreview() (CameraFile, error)
+
+	Update() error
+	Config() (*CameraWidget, error)
+	SetConfig() error
+
+	WaitForEvent(timeout int) (*CameraEvent, error)
+	Events(ctx context.Context, idle time.Duration) *EventStream
+}
+
+var (
+	_ Backend = (*Camera)(nil)
+	_ Backend = (*Fake)(nil)
+)
+
+type folderLister interface {
+	ListFolders(folder string) ([]string, error)
+}
+
+type contextFolderLister struct {
+	ctx context.Context
+	c   *Camera
+}
+
+func (l contextFolderLister) ListFolders(folder string) ([]string, error) {
+	return l.c.ListFoldersContext(l.ctx, folder)
+}
+
+func rListFolders(l folderLister, folder string) ([]string, error) {
+	folders := make([]string, 0)
+	path := folder
+	if !strings.HasSuffix(path, "/") {
+		path = path + "/"
+	}
+	subfolders, err := l.ListFolders(path)
+	if err != nil {
+		return folders, err
+	}
+	for _, sub := range subfolders {
+		subPath := path + sub
+		folders = append(folders, subPath)
+		subResults, err := rListFolders(l, subPath)
+		if err != nil {
+			return folders, err
+		}
+		folders = append(folders, subResults...)
+	}
+
+	return folders, nil
+}
@@ -0,0 +1,340 @@
+package gphoto2go
+
+import (
+	"container/list"
+	"context"
+	"path"
+	"sync"
+)
+
+const (
+	defaultCacheBlockSize = 64 * 1024
+	defaultCacheBlocks    = 256
+	defaultCacheReadAhead = 4
+)
+
+// BlockCacheOptions configure a BlockCache.
+type BlockCacheOptions struct {
+	// BlockSize is the size of the aligned blocks files are read in, 64KiB if zero.
+	BlockSize int
+	// Blocks is the number of blocks kept in memory, 256 if zero.
+	Blocks int
+	// ReadAhead is the number of blocks fetched along with a missing block
+	// when a file is read sequentially, 4 if zero. Negative disables read-ahead.
+	ReadAhead int
+}
+
+// CacheStats counts the block lookups of a BlockCache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Prefetched is the number of blocks read ahead.
+	Prefetched uint64
+	// Evicted is the number of blocks dropped to make room.
+	Evicted uint64
+}
+
+type blockKey struct {
+	// owner tells apart the cameras sharing the cache, see ownedCache.
+	owner uint64
+	path  string
+	typ   FileType
+	index int64
+}
+
+type cacheBlock struct {
+	key  blockKey
+	data []byte
+}
+
+// BlockCache caches the blocks read by ReadSeekers, shared by all readers of a file.
+// Many small reads, like parsing EXIF headers, are served from a few transfers
+// of whole blocks, and sequential reads fetch the following blocks in the same transfer.
+// Blocks are evicted least recently used first.
+type BlockCache struct {
+	blockSize int64
+	capacity  int
+	readAhead int
+
+	mu     sync.Mutex
+	lru    *list.List
+	blocks map[blockKey]*list.Element
+	stats  CacheStats
+	owners uint64
+}
+
+// NewBlockCache creates a BlockCache, see Camera.SetBlockCache.
+func NewBlockCache(opts BlockCacheOptions) *BlockCache {
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = defaultCacheBlockSize
+	}
+	if opts.Blocks <= 0 {
+		opts.Blocks = defaultCacheBlocks
+	}
+	if opts.ReadAhead == 0 {
+		opts.ReadAhead = defaultCacheReadAhead
+	}
+	if opts.ReadAhead < 0 {
+		opts.ReadAhead = 0
+	}
+
+	return &BlockCache{
+		blockSize: int64(opts.BlockSize),
+		capacity:  opts.Blocks,
+		readAhead: opts.ReadAhead,
+		lru:       list.New(),
+		blocks:    make(map[blockKey]*list.Element),
+	}
+}
+
+// Stats returns the current statistics.
+func (b *BlockCache) Stats() CacheStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+// Invalidate drops the cached blocks of a file and its previews and such,
+// e.g. after it changed on the camera. If the cache is shared,
+// the blocks of the file are dropped for every camera.
+func (b *BlockCache) Invalidate(folder, file string) {
+	p := path.Join(folder, file)
+	b.invalidate(func(key blockKey) bool { return key.path == p })
+}
+
+func (b *BlockCache) invalidate(match func(key blockKey) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for e := b.lru.Front(); e != nil; {
+		next := e.Next()
+		if blk := e.Value.(*cacheBlock); match(blk.key) {
+			b.lru.Remove(e)
+			delete(b.blocks, blk.key)
+		}
+		e = next
+	}
+}
+
+// Reset drops all cached blocks and clears the statistics.
+func (b *BlockCache) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lru.Init()
+	b.blocks = make(map[blockKey]*list.Element)
+	b.stats = CacheStats{}
+}
+
+func (b *BlockCache) get(key blockKey) ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.blocks[key]
+	if !ok {
+		b.stats.Misses++
+		return nil, false
+	}
+
+	b.stats.Hits++
+	b.lru.MoveToFront(e)
+	return e.Value.(*cacheBlock).data, true
+}
+
+func (b *BlockCache) put(key blockKey, data []byte, prefetched bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if prefetched {
+		b.stats.Prefetched++
+	}
+
+	if e, ok := b.blocks[key]; ok {
+		e.Value.(*cacheBlock).data = data
+		b.lru.MoveToFront(e)
+		return
+	}
+
+	b.blocks[key] = b.lru.PushFront(&cacheBlock{key: key, data: data})
+	for b.lru.Len() > b.capacity {
+		e := b.lru.Back()
+		b.lru.Remove(e)
+		delete(b.blocks, e.Value.(*cacheBlock).key)
+		b.stats.Evicted++
+	}
+}
+
+// ownedCache is the BlockCache of a single camera,
+// its id keeps the files apart from those of other cameras sharing the cache.
+type ownedCache struct {
+	bc *BlockCache
+	id uint64
+}
+
+// owner returns a view of the cache for a new camera.
+func (b *BlockCache) owner() *ownedCache {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.owners++
+	return &ownedCache{bc: b, id: b.owners}
+}
+
+// invalidate drops the cached blocks of a file of the camera.
+func (o *ownedCache) invalidate(folder, file string) {
+	p := path.Join(folder, file)
+	o.bc.invalidate(func(key blockKey) bool { return key.owner == o.id && key.path == p })
+}
+
+// invalidateFolder drops the cached blocks of all files in folder of the camera.
+func (o *ownedCache) invalidateFolder(folder string) {
+	folder = path.Clean(folder)
+	o.bc.invalidate(func(key blockKey) bool { return key.owner == o.id && path.Dir(key.path) == folder })
+}
+
+// source wraps src, reading the data of type typ of the file at p through the cache.
+func (o *ownedCache) source(src fileSource, p string, typ FileType) fileSource {
+	return &cachedSource{src: src, cache: o.bc, owner: o.id, path: p, typ: typ, next: -1, fileSize: -1}
+}
+
+// SetBlockCache makes the ReadSeekers created afterwards read through bc,
+// which may be shared with other cameras. A nil bc disables caching.
+// Deleting a file drops its cached blocks.
+func (c *Camera) SetBlockCache(bc *BlockCache) {
+	var o *ownedCache
+	if bc != nil {
+		o = bc.owner()
+	}
+
+	c.ctxMu.Lock()
+	c.blockCache = o
+	c.ctxMu.Unlock()
+}
+
+func (c *Camera) cache() *ownedCache {
+	c.ctxMu.Lock()
+	defer c.ctxMu.Unlock()
+	return c.blockCache
+}
+
+// SetBlockCache is Camera.SetBlockCache.
+func (f *Fake) SetBlockCache(bc *BlockCache) {
+	var o *ownedCache
+	if bc != nil {
+		o = bc.owner()
+	}
+
+	f.mu.Lock()
+	f.blockCache = o
+	f.mu.Unlock()
+}
+
+// cachedSource reads whole blocks from src, tracking which block a sequential read needs next.
+type cachedSource struct {
+	src   fileSource
+	cache *BlockCache
+	owner uint64
+	path  string
+	typ   FileType
+
+	mu       sync.Mutex
+	next     int64
+	sizeDone bool
+	fileSize int64
+	sizeErr  error
+}
+
+func (s *cachedSource) readAt(ctx context.Context, p []byte, off int64) (int, error) {
+	bs := s.cache.blockSize
+	index := off / bs
+
+	s.mu.Lock()
+	sequential := index == s.next
+	s.next = index + 1
+	s.mu.Unlock()
+
+	data, ok := s.cache.get(blockKey{s.owner, s.path, s.typ, index})
+	if !ok {
+		blocks := 1
+		if sequential {
+			blocks += s.cache.readAhead
+		}
+
+		var err error
+		if data, err = s.fetch(ctx, index, blocks); err != nil {
+			return 0, err
+		}
+	}
+
+	start := off - index*bs
+	if start >= int64(len(data)) {
+		return 0, nil
+	}
+
+	return copy(p, data[start:]), nil
+}
+
+// fetch reads blocks starting at index in as few reads as the driver allows,
+// caches them and returns the first one. It stops early at the end of the file.
+func (s *cachedSource) fetch(ctx context.Context, index int64, blocks int) ([]byte, error) {
+	bs := s.cache.blockSize
+	length := int64(blocks) * bs
+	// Drivers may fail reads past the end of a file.
+	if size, err := s.size(ctx); err == nil {
+		if remaining := size - index*bs; remaining < length {
+			length = remaining
+		}
+		if length < 0 {
+			length = 0
+		}
+	}
+
+	buf := make([]byte, length)
+	var n int
+	for n < len(buf) {
+		m, err := s.src.readAt(ctx, buf[n:], index*bs+int64(n))
+		if err != nil {
+			return nil, err
+		}
+		if m == 0 {
+			break
+		}
+		n += m
+	}
+	buf = buf[:n]
+
+	var first []byte
+	for i := 0; i < blocks; i++ {
+		start := int64(i) * bs
+		if start >= int64(n) && i != 0 {
+			break
+		}
+
+		end := start + bs
+		if end > int64(n) {
+			end = int64(n)
+		}
+		blk := buf[start:end:end]
+		if i == 0 {
+			first = blk
+		}
+		s.cache.put(blockKey{s.owner, s.path, s.typ, index + int64(i)}, blk, i != 0)
+	}
+
+	return first, nil
+}
+
+func (s *cachedSource) size(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.sizeDone {
+		size, err := s.src.size(ctx)
+		if err != nil && !sizeFinal(err) {
+			return 0, err
+		}
+		s.fileSize, s.sizeErr, s.sizeDone = size, err, true
+	}
+
+	return s.fileSize, s.sizeErr
+}
+
+func (s *cachedSource) close() {
+	s.src.close()
+}
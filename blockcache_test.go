@@ -0,0 +1,150 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func newCachedFake(t *testing.T, size int, opts BlockCacheOptions) (*Fake, *BlockCache, []byte) {
+	t.Helper()
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	f.AddFile("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2", data)
+
+	bc := NewBlockCache(opts)
+	f.SetBlockCache(bc)
+	return f, bc, data
+}
+
+func TestBlockCacheSmallReads(t *testing.T) {
+	f, bc, data := newCachedFake(t, 100, BlockCacheOptions{BlockSize: 16, ReadAhead: -1})
+
+	for i := 0; i < 2; i++ {
+		r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+		p := make([]byte, 4)
+		for _, off := range []int64{0, 4, 8, 12} {
+			if _, err := r.ReadAt(p, off); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p, data[off:off+4]) {
+				t.Errorf("expected %v at %d, got %v", data[off:off+4], off, p)
+			}
+		}
+		r.Close()
+	}
+
+	if s := bc.Stats(); s.Misses != 1 || s.Hits != 7 {
+		t.Errorf("expected 1 miss and 7 hits, got %+v", s)
+	}
+}
+
+func TestBlockCacheReadAhead(t *testing.T) {
+	f, bc, data := newCachedFake(t, 100, BlockCacheOptions{BlockSize: 16, ReadAhead: 3})
+
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected the file contents, got %v", got)
+	}
+
+	// The first read is not known to be sequential yet,
+	// the second fetches blocks 1 to 4 and the third 5 and 6.
+	if s := bc.Stats(); s.Misses != 3 || s.Prefetched != 4 || s.Hits != 4 {
+		t.Errorf("expected 3 misses, 4 prefetched blocks and 4 hits, got %+v", s)
+	}
+}
+
+func TestBlockCacheEviction(t *testing.T) {
+	f, bc, _ := newCachedFake(t, 64, BlockCacheOptions{BlockSize: 16, Blocks: 2, ReadAhead: -1})
+
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+	p := make([]byte, 1)
+	for _, off := range []int64{0, 16, 32, 0} {
+		if _, err := r.ReadAt(p, off); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s := bc.Stats(); s.Misses != 4 || s.Evicted != 2 {
+		t.Errorf("expected 4 misses and 2 evictions, got %+v", s)
+	}
+}
+
+func TestBlockCacheInvalidate(t *testing.T) {
+	f, bc, _ := newCachedFake(t, 16, BlockCacheOptions{BlockSize: 16})
+
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+
+	if err := f.DeleteFile("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2"); err != nil {
+		t.Fatal(err)
+	}
+
+	r = f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+	if _, err := r.ReadAt(make([]byte, 1), 0); err == nil {
+		t.Error("expected reading a deleted file to fail")
+	}
+	if s := bc.Stats(); s.Hits != 0 {
+		t.Errorf("expected no hits after deleting, got %+v", s)
+	}
+}
+
+func TestBlockCacheShared(t *testing.T) {
+	bc := NewBlockCache(BlockCacheOptions{BlockSize: 16})
+	var fakes []*Fake
+	for _, data := range []string{"first camera", "second camera"} {
+		f, err := NewFake()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.AddFile("/store_00010001/DCIM/100FAKE", "IMG_0001.JPG", []byte(data))
+		f.SetBlockCache(bc)
+		fakes = append(fakes, f)
+	}
+
+	read := func(f *Fake) string {
+		r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.JPG")
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(got)
+	}
+
+	for i := 0; i < 2; i++ {
+		if got := read(fakes[0]); got != "first camera" {
+			t.Errorf("expected the file of the first camera, got %q", got)
+		}
+		if got := read(fakes[1]); got != "second camera" {
+			t.Errorf("expected the file of the second camera, got %q", got)
+		}
+	}
+	if s := bc.Stats(); s.Hits != 2 {
+		t.Errorf("expected each camera to hit its own blocks, got %+v", s)
+	}
+
+	if err := fakes[0].DeleteFile("/store_00010001/DCIM/100FAKE", "IMG_0001.JPG"); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(fakes[1]); got != "second camera" || bc.Stats().Hits != 3 {
+		t.Errorf("expected deleting on one camera to keep the blocks of the other, got %q %+v", got, bc.Stats())
+	}
+}
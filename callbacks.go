@@ -0,0 +1,94 @@
+package gphoto2go
+
+// Exported callbacks for libgphoto2.
+// A file with //export directives can only have declarations in its preamble,
+// the C glue registering these lives next to the code using it.
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdint.h>
+import "C"
+import (
+	"log/slog"
+	"runtime/cgo"
+	"unsafe"
+)
+
+//export gphoto2goCancel
+func gphoto2goCancel(_ *C.GPContext, data unsafe.Pointer) C.GPContextFeedback {
+	c := cgo.Handle(uintptr(data)).Value().(*Camera)
+	if c.cancelRequested() {
+		return C.GP_CONTEXT_FEEDBACK_CANCEL
+	}
+
+	return C.GP_CONTEXT_FEEDBACK_OK
+}
+
+//export gphoto2goProgressStart
+func gphoto2goProgressStart(_ *C.GPContext, target C.float, text *C.char, data unsafe.Pointer) C.uint {
+	c := cgo.Handle(uintptr(data)).Value().(*Camera)
+	return c.progressStart(float64(target), C.GoString(text))
+}
+
+//export gphoto2goProgressUpdate
+func gphoto2goProgressUpdate(_ *C.GPContext, id C.uint, current C.float, data unsafe.Pointer) {
+	c := cgo.Handle(uintptr(data)).Value().(*Camera)
+	c.progressUpdate(id, float64(current))
+}
+
+//export gphoto2goProgressStop
+func gphoto2goProgressStop(_ *C.GPContext, id C.uint, data unsafe.Pointer) {
+	c := cgo.Handle(uintptr(data)).Value().(*Camera)
+	c.progressStop(id)
+}
+
+//export gphoto2goContextError
+func gphoto2goContextError(_ *C.GPContext, text *C.char, data unsafe.Pointer) {
+	c := cgo.Handle(uintptr(data)).Value().(*Camera)
+	c.logContext(slog.LevelError, "error", C.GoString(text))
+}
+
+//export gphoto2goContextStatus
+func gphoto2goContextStatus(_ *C.GPContext, text *C.char, data unsafe.Pointer) {
+	c := cgo.Handle(uintptr(data)).Value().(*Camera)
+	c.logContext(slog.LevelInfo, "status", C.GoString(text))
+}
+
+//export gphoto2goContextMessage
+func gphoto2goContextMessage(_ *C.GPContext, text *C.char, data unsafe.Pointer) {
+	c := cgo.Handle(uintptr(data)).Value().(*Camera)
+	c.logContext(slog.LevelWarn, "message", C.GoString(text))
+}
+
+//export gphoto2goLog
+func gphoto2goLog(level C.GPLogLevel, domain, str *C.char, _ unsafe.Pointer) {
+	logMessage(level, C.GoString(domain), C.GoString(str))
+}
+
+//export gphoto2goFileSize
+func gphoto2goFileSize(priv unsafe.Pointer, size *C.uint64_t) C.int {
+	h := cgo.Handle(uintptr(priv)).Value().(*fileHandler)
+	if h.r != nil {
+		*size = C.uint64_t(h.size)
+	} else {
+		*size = C.uint64_t(h.n)
+	}
+	return C.GP_OK
+}
+
+//export gphoto2goFileRead
+func gphoto2goFileRead(priv unsafe.Pointer, data *C.uchar, length *C.uint64_t) C.int {
+	h := cgo.Handle(uintptr(priv)).Value().(*fileHandler)
+	if h.r == nil {
+		return C.GP_ERROR_NOT_SUPPORTED
+	}
+	n, ret := h.read(unsafe.Slice((*byte)(unsafe.Pointer(data)), int(*length)))
+	*length = C.uint64_t(n)
+	return ret
+}
+
+//export gphoto2goFileWrite
+func gphoto2goFileWrite(priv unsafe.Pointer, data *C.uchar, length *C.uint64_t) C.int {
+	h := cgo.Handle(uintptr(priv)).Value().(*fileHandler)
+	return h.write(unsafe.Slice((*byte)(unsafe.Pointer(data)), int(*length)))
+}
@@ -5,77 +5,204 @@ package gphoto2go
 // #include <stdlib.h>
 import "C"
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"path"
+	"runtime/cgo"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
+// errNoConfig is returned by Config when the configuration was never read.
+var errNoConfig = errors.New("no configuration, see Update")
+
 // Camera struct
+//
+// Methods with a Context suffix abort when their context.Context is done,
+// the others are shorthands using context.Background().
+//
+// A Camera is safe for concurrent use, all operations are queued and
+// run one at a time on a dedicated OS thread, see Priority.
+// Widgets returned by Config are not synchronized and are freed by Update.
 type Camera struct {
 	camera    *C.Camera
 	context   *C.GPContext
 	abilities C.CameraAbilities
-	config    CameraWidget
-	err       error
+
+	// mu guards the configuration tree and the init error
+	mu     sync.Mutex
+	config *C.CameraWidget
+	err    error
+
+	handle cgo.Handle
+	// cancelGen is incremented by Cancel, aborting the operations queued before
+	cancelGen int32
+	queue     opQueue
+
+	// ctxMu guards the state of the running operation
+	ctxMu         sync.Mutex
+	ctx           context.Context
+	gen           int32
+	path          string
+	quiet         bool // drop the progress reported by the driver
+	progressFunc  ProgressFunc
+	progress      map[C.uint]*Progress
+	progressID    C.uint
+	logger        *slog.Logger
+	configRefresh bool
+	blockCache    *ownedCache
 }
 
 // Init creates a GPhoto2 context, the camera object, inits it, then obtains the camera's abilities and configuration.
-// Returns error if any step fails and nil otherwise
+// Returns error if any step fails and nil otherwise.
+// The first camera libgphoto2 finds is used, see Detect and Open to select a specific one.
 func (c *Camera) Init() error {
-	c.context = C.gp_context_new()
+	return c.InitContext(context.Background())
+}
+
+// InitContext is Init with a context.
+// The camera is released when it fails, Init can be called again.
+func (c *Camera) InitContext(ctx context.Context) error {
+	c.mu.Lock()
+	c.err = nil
+	c.mu.Unlock()
+
+	c.newContext()
 
 	C.gp_camera_new(&c.camera)
-	if c.err = cameraResultToError(C.gp_camera_init(c.camera, c.context)); c.err != nil {
-		return c.err
-	} else if c.err = cameraResultToError(C.gp_camera_get_abilities(c.camera, &c.abilities)); c.err != nil {
-		return c.err
-	} else if c.err = c.Update(); c.err != nil {
-		return c.err
+	if err := c.init(ctx); err != nil {
+		c.free()
+		return err
 	}
 
 	return nil
 }
 
-// Update re-initializes camera data that can be changed dynamically
-func (c *Camera) Update() error {
-	p := C.CString("")
-	defer C.free(unsafe.Pointer(p))
-	if c.err = cameraResultToError(C.gp_widget_new(C.GP_WIDGET_WINDOW, p, &c.config.widget)); c.err != nil {
-		C.free(unsafe.Pointer(c.config.widget))
-		return c.err
-	} else if c.err = cameraResultToError(C.gp_camera_get_config(c.camera, &c.config.widget, c.context)); c.err != nil {
-		c.config.Free()
-		return c.err
+func (c *Camera) init(ctx context.Context) error {
+	if err := c.call(ctx, func() C.int { return C.gp_camera_init(c.camera, c.context) }); err != nil {
+		return c.fail(err)
+	} else if err := cameraResultToError(C.gp_camera_get_abilities(c.camera, &c.abilities)); err != nil {
+		return c.fail(err)
+	} else if err := c.UpdateContext(ctx); err != nil {
+		return c.fail(err)
 	}
 
 	return nil
 }
 
-// Exit func
+// fail records err as the reason the camera is unusable and returns it.
+// Cancellations only affect the cancelled operation and are not recorded.
+func (c *Camera) fail(err error) error {
+	if !isCode(err, ErrCancel) {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// initErr returns the error that made the camera unusable, if any.
+func (c *Camera) initErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Update re-initializes camera data that can be changed dynamically
+//
+// It reads a new configuration tree and frees the previous one,
+// widgets obtained from it must not be used afterwards.
+// On failure the previous tree is kept.
+func (c *Camera) Update() error {
+	return c.UpdateContext(context.Background())
+}
+
+// UpdateContext is Update with a context.
+func (c *Camera) UpdateContext(ctx context.Context) error {
+	return c.call(ctx, func() C.int {
+		var tree *C.CameraWidget
+		if ret := C.gp_camera_get_config(c.camera, &tree, c.context); ret < C.GP_OK {
+			return ret
+		}
+
+		// Swapped on the queue, no operation is using the previous tree.
+		c.mu.Lock()
+		prev := c.config
+		c.config = tree
+		c.mu.Unlock()
+		if prev != nil {
+			C.gp_widget_free(prev)
+		}
+
+		return C.GP_OK
+	})
+}
+
+// configTree returns the configuration tree, nil if it was never read.
+func (c *Camera) configTree() *CameraWidget {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.config == nil {
+		return nil
+	}
+	return &CameraWidget{c.config}
+}
+
+// Exit closes the connection to the camera and stops the thread running its operations.
+// Following operations reconnect.
 func (c *Camera) Exit() error {
-	err := C.gp_camera_exit(c.camera, c.context)
-	return cameraResultToError(err)
+	err := c.call(context.Background(), func() C.int { return C.gp_camera_exit(c.camera, c.context) })
+	c.queue.stop()
+	return err
 }
 
-// Cancel func
+// Close exits the camera and releases it, unlike Exit.
+// The Camera can only be used again after Init.
+func (c *Camera) Close() error {
+	if c.camera == nil {
+		return nil
+	}
+
+	err := c.Exit()
+	c.free()
+	return err
+}
+
+// Cancel aborts the running operation, if its driver supports cancellation,
+// and the queued ones, which then return an ErrCancel error without running.
+// Operations started after Cancel returns are not affected.
+// Prefer passing a context.Context to the Context methods,
+// which only affects that operation.
 func (c *Camera) Cancel() {
-	C.gp_context_cancel(c.context)
+	atomic.AddInt32(&c.cancelGen, 1)
 }
 
 // TriggerCapture func
 func (c *Camera) TriggerCapture() error {
-	err := C.gp_camera_trigger_capture(c.camera, c.context)
-	return cameraResultToError(err)
+	return c.TriggerCaptureContext(context.Background())
+}
+
+// TriggerCaptureContext is TriggerCapture with a context.
+func (c *Camera) TriggerCaptureContext(ctx context.Context) error {
+	return c.call(ctx, func() C.int { return C.gp_camera_trigger_capture(c.camera, c.context) })
 }
 
 // TriggerCaptureToFile func
 func (c *Camera) TriggerCaptureToFile() (CameraFilePath, error) {
+	return c.TriggerCaptureToFileContext(context.Background())
+}
+
+// TriggerCaptureToFileContext is TriggerCaptureToFile with a context.
+func (c *Camera) TriggerCaptureToFileContext(ctx context.Context) (CameraFilePath, error) {
 	var path CameraFilePath
 	var _path C.CameraFilePath
-	err := cameraResultToError(C.gp_camera_capture(c.camera, captureImage, &_path, c.context))
+	err := c.call(ctx, func() C.int { return C.gp_camera_capture(c.camera, captureImage, &_path, c.context) })
 	if err != nil {
 		return path, err
 	}
@@ -85,38 +212,78 @@ func (c *Camera) TriggerCaptureToFile() (CameraFilePath, error) {
 }
 
 // DownloadFile saves the file from a TriggerCaptureToFile return
-func (c *Camera) DownloadFile(cfp CameraFilePath, filePath string) (err error) {
-	cfr := c.FileReader(cfp.Folder, cfp.Name)
-	defer cfr.Close()
+func (c *Camera) DownloadFile(cfp CameraFilePath, filePath string) error {
+	return c.DownloadFileContext(context.Background(), cfp, filePath)
+}
 
-	if fileWriter, err := os.Create(filePath); err == nil {
-		io.Copy(fileWriter, cfr)
-		return nil
+// DownloadFileContext is DownloadFile with a context.
+func (c *Camera) DownloadFileContext(ctx context.Context, cfp CameraFilePath, filePath string) error {
+	fileWriter, err := os.Create(filePath)
+	if err != nil {
+		return err
 	}
-	return err
+
+	if _, err := c.DownloadToContext(ctx, cfp.Folder, cfp.Name, fileWriter); err != nil {
+		fileWriter.Close()
+		return err
+	}
+
+	return fileWriter.Close()
 }
 
 // CaptureToFile func
 func (c *Camera) CaptureToFile(filePath string) error {
-	cfp, err := c.TriggerCaptureToFile()
+	return c.CaptureToFileContext(context.Background(), filePath)
+}
+
+// CaptureToFileContext is CaptureToFile with a context.
+func (c *Camera) CaptureToFileContext(ctx context.Context, filePath string) error {
+	cfp, err := c.TriggerCaptureToFileContext(ctx)
 	if err != nil {
 		return err
 	}
-	err = c.DownloadFile(cfp, filePath)
-	return err
+	return c.DownloadFileContext(ctx, cfp, filePath)
 }
 
-// AsyncWaitForEvent func
-func (c *Camera) AsyncWaitForEvent(timeout int) chan *CameraEvent {
+// WaitForEvent waits up to timeout milliseconds for the next camera event.
+// A timeout results in an event of type EventTimeout, not an error.
+func (c *Camera) WaitForEvent(timeout int) (*CameraEvent, error) {
+	return c.WaitForEventContext(context.Background(), timeout)
+}
+
+// WaitForEventContext is WaitForEvent with a context.
+func (c *Camera) WaitForEventContext(ctx context.Context, timeout int) (*CameraEvent, error) {
+	return c.waitForEvent(ctx, timeout, PriorityNormal)
+}
+
+func (c *Camera) waitForEvent(ctx context.Context, timeout int, p Priority) (*CameraEvent, error) {
 	var eventType C.CameraEventType
 	var vp unsafe.Pointer
+
+	err := c.callPriority(ctx, p, func() C.int {
+		return C.gp_camera_wait_for_event(c.camera, C.int(timeout), &eventType, &vp, c.context)
+	})
+	// The event data is allocated by libgphoto2 and owned by the caller.
 	defer C.free(vp)
+	if err != nil {
+		return nil, err
+	}
 
+	return cCameraEventToGoCameraEvent(vp, eventType), nil
+}
+
+// AsyncWaitForEvent func
+//
+// Deprecated: use Events, which reports errors and can be stopped.
+func (c *Camera) AsyncWaitForEvent(timeout int) chan *CameraEvent {
 	ch := make(chan *CameraEvent)
 
 	go func() {
-		C.gp_camera_wait_for_event(c.camera, C.int(timeout), &eventType, &vp, c.context)
-		ch <- cCameraEventToGoCameraEvent(vp, eventType)
+		ev, err := c.WaitForEvent(timeout)
+		if err != nil {
+			ev = &CameraEvent{Type: EventUnknown}
+		}
+		ch <- ev
 	}()
 
 	return ch
@@ -124,18 +291,25 @@ func (c *Camera) AsyncWaitForEvent(timeout int) chan *CameraEvent {
 
 // ListFolders func
 func (c *Camera) ListFolders(folder string) ([]string, error) {
+	return c.ListFoldersContext(context.Background(), folder)
+}
+
+// ListFoldersContext is ListFolders with a context.
+func (c *Camera) ListFoldersContext(ctx context.Context, folder string) ([]string, error) {
 	if folder == "" {
 		folder = "/"
 	}
 
 	var cameraList *C.CameraList
 	C.gp_list_new(&cameraList)
-	defer C.free(unsafe.Pointer(cameraList))
+	defer C.gp_list_free(cameraList)
 
 	cFolder := C.CString(folder)
 	defer C.free(unsafe.Pointer(cFolder))
 
-	if err := cameraResultToError(C.gp_camera_folder_list_folders(c.camera, cFolder, cameraList, c.context)); err != nil {
+	if err := c.call(ctx, func() C.int {
+		return C.gp_camera_folder_list_folders(c.camera, cFolder, cameraList, c.context)
+	}); err != nil {
 		return []string{}, err
 	}
 	folderMap, _ := cameraListToMap(cameraList)
@@ -152,30 +326,21 @@ func (c *Camera) ListFolders(folder string) ([]string, error) {
 
 // RListFolders func
 func (c *Camera) RListFolders(folder string) ([]string, error) {
-	folders := make([]string, 0)
-	path := folder
-	if !strings.HasSuffix(path, "/") {
-		path = path + "/"
-	}
-	subfolders, err := c.ListFolders(path)
-	if err != nil {
-		return folders, err
-	}
-	for _, sub := range subfolders {
-		subPath := path + sub
-		folders = append(folders, subPath)
-		subResults, err := c.RListFolders(subPath)
-		if err != nil {
-			return folders, err
-		}
-		folders = append(folders, subResults...)
-	}
+	return c.RListFoldersContext(context.Background(), folder)
+}
 
-	return folders, nil
+// RListFoldersContext is RListFolders with a context.
+func (c *Camera) RListFoldersContext(ctx context.Context, folder string) ([]string, error) {
+	return rListFolders(contextFolderLister{ctx, c}, folder)
 }
 
 // ListFiles func
 func (c *Camera) ListFiles(folder string) ([]string, error) {
+	return c.ListFilesContext(context.Background(), folder)
+}
+
+// ListFilesContext is ListFiles with a context.
+func (c *Camera) ListFilesContext(ctx context.Context, folder string) ([]string, error) {
 	if folder == "" {
 		folder = "/"
 	}
@@ -186,12 +351,14 @@ func (c *Camera) ListFiles(folder string) ([]string, error) {
 
 	var cameraList *C.CameraList
 	C.gp_list_new(&cameraList)
-	defer C.free(unsafe.Pointer(cameraList))
+	defer C.gp_list_free(cameraList)
 
 	cFolder := C.CString(folder)
 	defer C.free(unsafe.Pointer(cFolder))
 
-	if err := cameraResultToError(C.gp_camera_folder_list_files(c.camera, cFolder, cameraList, c.context)); err != nil {
+	if err := c.call(ctx, func() C.int {
+		return C.gp_camera_folder_list_files(c.camera, cFolder, cameraList, c.context)
+	}); err != nil {
 		return []string{}, err
 	}
 	fileNameMap, _ := cameraListToMap(cameraList)
@@ -206,8 +373,20 @@ func (c *Camera) ListFiles(folder string) ([]string, error) {
 	return names, nil
 }
 
-// FileReader func
+// FileReader downloads a file and returns a reader of its contents.
+// The whole file is held in memory, use DownloadTo to stream large files.
+// Download errors are returned by its Read method.
 func (c *Camera) FileReader(folder string, fileName string) io.ReadCloser {
+	cfr, err := c.FileReaderContext(context.Background(), folder, fileName)
+	if err != nil {
+		return io.NopCloser(errReader{err})
+	}
+	return cfr
+}
+
+// FileReaderContext is FileReader with a context and options, see WithFileType to read thumbnails and such.
+func (c *Camera) FileReaderContext(ctx context.Context, folder string, fileName string, opts ...FileOption) (io.ReadCloser, error) {
+	o := newFileOptions(opts)
 	cfr := new(cameraFileReader)
 	cfr.camera = c
 	cfr.folder = folder
@@ -220,147 +399,85 @@ func (c *Camera) FileReader(folder string, fileName string) io.ReadCloser {
 	defer C.free(unsafe.Pointer(cFileName))
 	defer C.free(unsafe.Pointer(cFolderName))
 
+	cType := C.CameraFileType(o.typ)
 	C.gp_file_new(&cfr.cCameraFile)
-	C.gp_camera_file_get(c.camera, cFolderName, cFileName, C.GP_FILE_TYPE_NORMAL, cfr.cCameraFile, c.context)
+	if err := c.callFile(ctx, o.priority, path.Join(folder, fileName), func() C.int {
+		return C.gp_camera_file_get(c.camera, cFolderName, cFileName, cType, cfr.cCameraFile, c.context)
+	}); err != nil {
+		cfr.Close()
+		return nil, err
+	}
 
 	var cSize C.ulong
 	C.gp_file_get_data_and_size(cfr.cCameraFile, &cfr.cBuffer, &cSize)
 
 	cfr.fullSize = uint64(cSize)
 
-	return cfr
+	return cfr, nil
 }
 
-type ReadSeeker struct {
-	c      *Camera
-	dir    *C.char
-	file   *C.char
-	offset int64
-	err    error
-}
-
-func (r *ReadSeeker) Seek(offset int64, whence int) (int64, error) {
-	n := r.offset
-	switch whence {
-	case io.SeekStart:
-		n = offset
-	case io.SeekCurrent:
-		n += offset
-	case io.SeekEnd:
-		return r.offset, errors.New("seekEnd not supported")
-	default:
-		return r.offset, errors.New("unknown whence")
-	}
-
-	if n < 0 {
-		return r.offset, errors.New("invalid negative offset")
-	}
-
-	r.offset = n
-	return r.offset, nil
+// Info returns the metadata of a file.
+func (c *Camera) Info(folder, file string) (*Info, error) {
+	return c.InfoContext(context.Background(), folder, file)
 }
 
-func (r *ReadSeeker) Read(p []byte) (int, error) {
-	if r.err != nil {
-		return 0, r.err
-	}
-	cSize := C.ulong(len(p))
-	cOffset := C.ulong(r.offset)
-	s := make([]C.char, len(p))
-	retval := C.gp_camera_file_read(
-		r.c.camera,
-		r.dir,
-		r.file,
-		C.GP_FILE_TYPE_NORMAL,
-		cOffset,
-		&s[0],
-		&cSize,
-		r.c.context,
-	)
-
-	err := cameraResultToError(retval)
+// InfoContext is Info with a context.
+func (c *Camera) InfoContext(ctx context.Context, folder, file string) (*Info, error) {
+	cInfo, err := c.fileInfo(ctx, folder, file)
 	if err != nil {
-		return 0, err
-	}
-
-	size := int(cSize)
-	r.offset += int64(size)
-
-	if size < len(p) {
-		r.err = io.EOF
-		err = io.EOF
-	}
-
-	for i := 0; i < size; i++ {
-		p[i] = byte(s[i])
+		return nil, err
 	}
 
-	return size, err
+	return newInfo(cInfo), nil
 }
 
-func (r *ReadSeeker) Close() error {
-	r.err = errors.New("can't read from closed reader")
-	C.free(unsafe.Pointer(r.dir))
-	C.free(unsafe.Pointer(r.file))
-	return nil
-}
-
-// ReadSeeker returns an io.ReadSeeker + io.Closer
-// that has no clue about the underlying filesize.
-// Uses gp_camera_file_read instead of gp_camera_file_get for increased performance.
-func (c *Camera) ReadSeeker(folder, file string) *ReadSeeker {
-	cFileName := C.CString(file)
-	cFolderName := C.CString(folder)
-	return &ReadSeeker{c: c, dir: cFolderName, file: cFileName}
-}
-
-type Info struct {
-	Size          int64
-	MTime         int64
-	Width, Height int
-}
-
-func (c *Camera) Info(folder, file string) (*Info, error) {
+func (c *Camera) fileInfo(ctx context.Context, folder, file string) (*C.CameraFileInfo, error) {
 	cInfo := new(C.CameraFileInfo)
 	cFileName := C.CString(file)
 	cFolderName := C.CString(folder)
 	defer C.free(unsafe.Pointer(cFileName))
 	defer C.free(unsafe.Pointer(cFolderName))
-	retval := C.gp_camera_file_get_info(
-		c.camera,
-		cFolderName,
-		cFileName,
-		cInfo,
-		c.context,
-	)
-
-	if err := cameraResultToError(retval); err != nil {
-		return nil, err
-	}
-
-	return &Info{
-		Size:   int64(cInfo.file.size),
-		MTime:  int64(cInfo.file.mtime),
-		Width:  int(cInfo.file.width),
-		Height: int(cInfo.file.height),
-	}, nil
+	err := c.call(ctx, func() C.int {
+		return C.gp_camera_file_get_info(
+			c.camera,
+			cFolderName,
+			cFileName,
+			cInfo,
+			c.context,
+		)
+	})
+
+	return cInfo, err
 }
 
 // DeleteFile func
 func (c *Camera) DeleteFile(folder, file string) error {
-	folderBytes := []byte(folder)
-	fileBytes := []byte(file)
-	//Convert the byte arrays into C pointers
+	return c.DeleteFileContext(context.Background(), folder, file)
+}
 
-	folderPointer := (*C.char)(unsafe.Pointer(&folderBytes[0]))
-	filePointer := (*C.char)(unsafe.Pointer(&fileBytes[0]))
-	return cameraResultToError(C.gp_camera_file_delete(c.camera, folderPointer, filePointer, c.context))
+// DeleteFileContext is DeleteFile with a context.
+func (c *Camera) DeleteFileContext(ctx context.Context, folder, file string) error {
+	cFolder := C.CString(folder)
+	cFile := C.CString(file)
+	defer C.free(unsafe.Pointer(cFolder))
+	defer C.free(unsafe.Pointer(cFile))
+	if bc := c.cache(); bc != nil {
+		defer bc.invalidate(folder, file)
+	}
+	return c.call(ctx, func() C.int { return C.gp_camera_file_delete(c.camera, cFolder, cFile, c.context) })
 }
 
 // CapturePreview func
 func (c *Camera) CapturePreview() (cf CameraFile, err error) {
+	return c.CapturePreviewContext(context.Background())
+}
+
+// CapturePreviewContext is CapturePreview with a context.
+func (c *Camera) CapturePreviewContext(ctx context.Context) (cf CameraFile, err error) {
 	C.gp_file_new(&cf.file)
-	if err := cameraResultToError(C.gp_camera_capture_preview(c.camera, cf.file, c.context)); err != nil {
+	if err := c.callPriority(ctx, PriorityHigh, func() C.int {
+		return C.gp_camera_capture_preview(c.camera, cf.file, c.context)
+	}); err != nil {
 		return cf, err
 	}
 	if err := cameraResultToError(C.gp_file_get_data_and_size(cf.file, &cf.buf, &cf.cSize)); err != nil {
@@ -431,22 +548,43 @@ func (c *Camera) Library() (string, error) {
 
 // Abilities func
 func (c *Camera) Abilities() (C.CameraAbilities, error) {
-	return c.abilities, c.err
+	return c.abilities, c.initErr()
 }
 
 // SetConfig func
 func (c *Camera) SetConfig() error {
-	if c.err != nil {
+	return c.SetConfigContext(context.Background())
+}
+
+// SetConfigContext is SetConfig with a context.
+func (c *Camera) SetConfigContext(ctx context.Context) error {
+	if err := c.initErr(); err != nil {
 		// something failed during camera init.  Bail!
-		return c.err
+		return err
 	}
-	if err := cameraResultToError(C.gp_camera_set_config(c.camera, c.config.widget, c.context)); err != nil {
-		return fmt.Errorf("error on C.gp_camera_set_config %v", err)
+	if err := c.call(ctx, func() C.int {
+		c.mu.Lock()
+		tree := c.config
+		c.mu.Unlock()
+		if tree == nil {
+			return C.GP_ERROR_BAD_PARAMETERS
+		}
+		return C.gp_camera_set_config(c.camera, tree, c.context)
+	}); err != nil {
+		return fmt.Errorf("error on C.gp_camera_set_config: %w", err)
 	}
 	return nil
 }
 
-// Config func
+// Config returns the configuration tree read by Init and Update,
+// values set on it are written by SetConfig.
 func (c *Camera) Config() (*CameraWidget, error) {
-	return &c.config, c.err
+	if err := c.initErr(); err != nil {
+		return nil, err
+	}
+	if config := c.configTree(); config != nil {
+		return config, nil
+	}
+
+	return nil, errNoConfig
 }
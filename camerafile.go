@@ -4,6 +4,7 @@ package gphoto2go
 // #include <gphoto2.h>
 // #include <stdlib.h>
 import "C"
+import "unsafe"
 
 // CameraFile struct
 type CameraFile struct {
@@ -11,3 +12,32 @@ type CameraFile struct {
 	cSize C.ulong
 	buf   *C.char
 }
+
+func newCameraFile(data []byte) (cf CameraFile, err error) {
+	if err = cameraResultToError(C.gp_file_new(&cf.file)); err != nil {
+		return cf, err
+	}
+	if len(data) != 0 {
+		cData := C.CBytes(data)
+		defer C.free(cData)
+		if err = cameraResultToError(C.gp_file_append(cf.file, (*C.char)(cData), C.ulong(len(data)))); err != nil {
+			return cf, err
+		}
+	}
+
+	err = cameraResultToError(C.gp_file_get_data_and_size(cf.file, &cf.buf, &cf.cSize))
+	return cf, err
+}
+
+// Data returns a copy of the file's contents.
+func (cf CameraFile) Data() []byte {
+	if cf.buf == nil {
+		return []byte{}
+	}
+	return C.GoBytes(unsafe.Pointer(cf.buf), C.int(cf.cSize))
+}
+
+// Free releases the file and its data.
+func (cf CameraFile) Free() error {
+	return cameraResultToError(C.gp_file_free(cf.file))
+}
@@ -6,14 +6,11 @@ package gphoto2go
 import "C"
 import (
 	"io"
-	"reflect"
 	"unsafe"
 )
 
-// Need to find a good buffer size
-// For now, let's try 1MB
-const fileReaderBufferSize = 20 * 1024 * 1024
-
+// cameraFileReader reads the data of a downloaded CameraFile,
+// straight from the buffer owned by libgphoto2.
 type cameraFileReader struct {
 	camera   *Camera
 	folder   string
@@ -24,8 +21,6 @@ type cameraFileReader struct {
 
 	cCameraFile *C.CameraFile
 	cBuffer     *C.char
-
-	buffer [fileReaderBufferSize]byte
 }
 
 func (cfr *cameraFileReader) Read(p []byte) (int, error) {
@@ -33,43 +28,22 @@ func (cfr *cameraFileReader) Read(p []byte) (int, error) {
 		return 0, io.ErrClosedPipe
 	}
 
-	n := uint64(len(p))
-
-	if n == 0 {
+	if len(p) == 0 {
 		return 0, nil
 	}
 
-	bufLen := uint64(len(cfr.buffer))
-	remaining := cfr.fullSize - cfr.offset
-
-	toRead := bufLen
-	if toRead > remaining {
-		toRead = remaining
-	}
-
-	if toRead > n {
-		toRead = n
+	if cfr.offset >= cfr.fullSize {
+		return 0, io.EOF
 	}
 
-	// From: https://code.google.com/p/go-wiki/wiki/cgo
-	// Turning C arrays into Go slices
-	sliceHeader := reflect.SliceHeader{
-		Data: uintptr(unsafe.Pointer(cfr.cBuffer)),
-		Len:  int(cfr.fullSize),
-		Cap:  int(cfr.fullSize),
-	}
-	goSlice := *(*[]C.char)(unsafe.Pointer(&sliceHeader))
-
-	for i := uint64(0); i < toRead; i++ {
-		p[i] = byte(goSlice[cfr.offset+i])
-	}
-
-	cfr.offset += toRead
+	data := unsafe.Slice((*byte)(unsafe.Pointer(cfr.cBuffer)), cfr.fullSize)
+	n := copy(p, data[cfr.offset:])
+	cfr.offset += uint64(n)
 
 	if cfr.offset < cfr.fullSize {
-		return int(toRead), nil
+		return n, nil
 	}
-	return int(toRead), io.EOF
+	return n, io.EOF
 }
 
 func (cfr *cameraFileReader) Close() error {
@@ -80,3 +54,7 @@ func (cfr *cameraFileReader) Close() error {
 	}
 	return nil
 }
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
@@ -5,7 +5,9 @@ package gphoto2go
 // #include <stdlib.h>
 import "C"
 import (
+	"errors"
 	"fmt"
+	"math"
 	"time"
 	"unsafe"
 )
@@ -15,10 +17,34 @@ type widgetValueType int
 const (
 	wvtString widgetValueType = iota
 	wvtNum
+	wvtFloat
 	wvtDate
 	wvtWeird
 )
 
+// ErrInvalidValue is wrapped by the errors of setting a widget to a value
+// of the wrong type or outside of its constraints.
+var ErrInvalidValue = errors.New("invalid widget value")
+
+// ErrReadOnly is returned when setting the value of a read-only widget.
+var ErrReadOnly = errors.New("widget is read-only")
+
+// WidgetType identifies the kind of a CameraWidget
+type WidgetType int
+
+// Widget types
+const (
+	WidgetWindow  WidgetType = C.GP_WIDGET_WINDOW
+	WidgetSection WidgetType = C.GP_WIDGET_SECTION
+	WidgetText    WidgetType = C.GP_WIDGET_TEXT
+	WidgetRange   WidgetType = C.GP_WIDGET_RANGE
+	WidgetToggle  WidgetType = C.GP_WIDGET_TOGGLE
+	WidgetRadio   WidgetType = C.GP_WIDGET_RADIO
+	WidgetMenu    WidgetType = C.GP_WIDGET_MENU
+	WidgetButton  WidgetType = C.GP_WIDGET_BUTTON
+	WidgetDate    WidgetType = C.GP_WIDGET_DATE
+)
+
 // WidgetTypeInfo struct
 type WidgetTypeInfo struct {
 	str   string
@@ -31,11 +57,11 @@ var widgetTypeTable = map[C.CameraWidgetType]WidgetTypeInfo{
 	C.GP_WIDGET_WINDOW:  WidgetTypeInfo{"Window", wvtWeird, C.GP_WIDGET_WINDOW, "Window widget This is the toplevel configuration widget. It should likely contain multiple widget seciton entries"},
 	C.GP_WIDGET_SECTION: WidgetTypeInfo{"Section", wvtWeird, C.GP_WIDGET_SECTION, "Section widget (think Tab)"},
 	C.GP_WIDGET_TEXT:    WidgetTypeInfo{"Text", wvtString, C.GP_WIDGET_TEXT, "Text widget"},
-	C.GP_WIDGET_RANGE:   WidgetTypeInfo{"Range", wvtNum, C.GP_WIDGET_RANGE, "Slider widget"},
+	C.GP_WIDGET_RANGE:   WidgetTypeInfo{"Range", wvtFloat, C.GP_WIDGET_RANGE, "Slider widget"},
 	C.GP_WIDGET_TOGGLE:  WidgetTypeInfo{"Toggle", wvtNum, C.GP_WIDGET_TOGGLE, "Toggle widget (think check box)"},
 	C.GP_WIDGET_RADIO:   WidgetTypeInfo{"Radio", wvtString, C.GP_WIDGET_RADIO, "Radio button widget"},
-	C.GP_WIDGET_MENU:    WidgetTypeInfo{"Menu", wvtNum, C.GP_WIDGET_MENU, "Menu widget (same as RADIO)"},
-	C.GP_WIDGET_BUTTON:  WidgetTypeInfo{"Button", wvtNum, C.GP_WIDGET_BUTTON, "Button press widget"},
+	C.GP_WIDGET_MENU:    WidgetTypeInfo{"Menu", wvtString, C.GP_WIDGET_MENU, "Menu widget (same as RADIO)"},
+	C.GP_WIDGET_BUTTON:  WidgetTypeInfo{"Button", wvtWeird, C.GP_WIDGET_BUTTON, "Button press widget"},
 	C.GP_WIDGET_DATE:    WidgetTypeInfo{"Date", wvtDate, C.GP_WIDGET_DATE, "Date entering widget"},
 }
 
@@ -44,30 +70,290 @@ func (wti *WidgetTypeInfo) Str() string {
 	return wti.str
 }
 
+// Type returns the widget type
+func (wti *WidgetTypeInfo) Type() WidgetType {
+	return WidgetType(wti.enum)
+}
+
 // CameraWidget struct
 type CameraWidget struct {
 	widget *C.CameraWidget
 }
 
-// SetValue func
+// NewWidget creates a detached widget, e.g. to build the configuration of a Fake.
+// Widgets appended to a parent are freed together with their root.
+func NewWidget(typ WidgetType, name, label string) (*CameraWidget, error) {
+	cLabel := C.CString(label)
+	defer C.free(unsafe.Pointer(cLabel))
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	w := new(CameraWidget)
+	if err := cameraResultToError(C.gp_widget_new(C.CameraWidgetType(typ), cLabel, &w.widget)); err != nil {
+		return nil, err
+	}
+	if err := cameraResultToError(C.gp_widget_set_name(w.widget, cName)); err != nil {
+		w.Free()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Append adds child as the last child of w
+func (w *CameraWidget) Append(child *CameraWidget) error {
+	return cameraResultToError(C.gp_widget_append(w.widget, child.widget))
+}
+
+// AddChoice adds a choice to a radio or menu widget
+func (w *CameraWidget) AddChoice(choice string) error {
+	cChoice := C.CString(choice)
+	defer C.free(unsafe.Pointer(cChoice))
+
+	return cameraResultToError(C.gp_widget_add_choice(w.widget, cChoice))
+}
+
+// SetRange sets the minimum, maximum and increment of a range widget
+func (w *CameraWidget) SetRange(min, max, step float64) error {
+	return cameraResultToError(C.gp_widget_set_range(w.widget, C.float(min), C.float(max), C.float(step)))
+}
+
+// SetReadonly marks the widget read-only
+func (w *CameraWidget) SetReadonly(readonly bool) error {
+	ro := C.int(0)
+	if readonly {
+		ro = 1
+	}
+	return cameraResultToError(C.gp_widget_set_readonly(w.widget, ro))
+}
+
+// SetValue sets the value of the widget from a Go value matching its type:
+// a string for text, radio and menu widgets, a number for ranges,
+// a bool or int for toggles and a time.Time or unix timestamp for dates.
+// The value is validated with the typed setters, e.g. SetFloat.
 func (w *CameraWidget) SetValue(v interface{}) error {
-	switch v.(type) {
-	case string:
-		cstr := C.CString(v.(string))
-		defer C.free(unsafe.Pointer(cstr))
+	typ, err := w.Type()
+	if err != nil {
+		return err
+	}
 
-		if err := cameraResultToError(C.gp_widget_set_value(w.widget, unsafe.Pointer(cstr))); err != nil {
-			return err
+	switch v := v.(type) {
+	case string:
+		return w.SetString(v)
+	case time.Time:
+		return w.SetTime(v)
+	case bool:
+		i := 0
+		if v {
+			i = 1
 		}
-	default:
-		if err := cameraResultToError(C.gp_widget_set_value(w.widget, unsafe.Pointer(&v))); err != nil {
+		return w.SetInt(i)
+	case float32:
+		return w.SetFloat(float64(v))
+	case float64:
+		return w.SetFloat(v)
+	case int:
+		return w.setInteger(typ, int64(v))
+	case int32:
+		return w.setInteger(typ, int64(v))
+	case int64:
+		return w.setInteger(typ, v)
+	}
+
+	return fmt.Errorf("%w: %T for a %s widget", ErrInvalidValue, v, typ.Str())
+}
+
+// setInteger sets a range, date or toggle widget from an integer.
+func (w *CameraWidget) setInteger(typ *WidgetTypeInfo, v int64) error {
+	switch typ.Type() {
+	case WidgetRange:
+		return w.SetFloat(float64(v))
+	case WidgetDate:
+		return w.SetTime(time.Unix(v, 0))
+	}
+	return w.SetInt(int(v))
+}
+
+// checkType returns an error if the widget is not one of types
+// or, when setting, is read-only.
+func (w *CameraWidget) checkType(set bool, types ...WidgetType) error {
+	typ, err := w.Type()
+	if err != nil {
+		return err
+	}
+
+	ok := false
+	for _, t := range types {
+		ok = ok || typ.Type() == t
+	}
+	if !ok {
+		return fmt.Errorf("%w: not supported by a %s widget", ErrInvalidValue, typ.Str())
+	}
+
+	if set {
+		if ro, err := w.Readonly(); err != nil {
 			return err
+		} else if ro {
+			return ErrReadOnly
 		}
 	}
 
 	return nil
 }
 
+// String returns the value of a text, radio or menu widget.
+func (w *CameraWidget) String() (string, error) {
+	if err := w.checkType(false, WidgetText, WidgetRadio, WidgetMenu); err != nil {
+		return "", err
+	}
+
+	var val *C.char
+	// val is owned by the widget, ToString() copies it
+	if err := cameraResultToError(C.gp_widget_get_value(w.widget, unsafe.Pointer(&val))); err != nil {
+		return "", err
+	}
+	return ToString(val), nil
+}
+
+// SetString sets the value of a text, radio or menu widget.
+func (w *CameraWidget) SetString(v string) error {
+	if err := w.checkType(true, WidgetText, WidgetRadio, WidgetMenu); err != nil {
+		return err
+	}
+
+	return w.setValue(v)
+}
+
+// Range returns the minimum, maximum and increment of a range widget.
+func (w *CameraWidget) Range() (min, max, step float64, err error) {
+	if err := w.checkType(false, WidgetRange); err != nil {
+		return 0, 0, 0, err
+	}
+
+	var cMin, cMax, cStep C.float
+	if err := cameraResultToError(C.gp_widget_get_range(w.widget, &cMin, &cMax, &cStep)); err != nil {
+		return 0, 0, 0, err
+	}
+	return float64(cMin), float64(cMax), float64(cStep), nil
+}
+
+// Float returns the value of a range widget.
+func (w *CameraWidget) Float() (float64, error) {
+	if err := w.checkType(false, WidgetRange); err != nil {
+		return 0, err
+	}
+
+	var val C.float
+	if err := cameraResultToError(C.gp_widget_get_value(w.widget, unsafe.Pointer(&val))); err != nil {
+		return 0, err
+	}
+	return float64(val), nil
+}
+
+// SetFloat sets the value of a range widget,
+// which must be within its range and a multiple of its increment from the minimum.
+func (w *CameraWidget) SetFloat(v float64) error {
+	if err := w.checkType(true, WidgetRange); err != nil {
+		return err
+	}
+
+	min, max, step, err := w.Range()
+	if err != nil {
+		return err
+	}
+	// The range and value are stored as float32, compare at that precision
+	// so values like 0.1 and those returned by Float are accepted.
+	f := float64(float32(v))
+	if math.IsNaN(v) || f < min || f > max {
+		return fmt.Errorf("%w: %v not in [%v, %v]", ErrInvalidValue, v, min, max)
+	}
+	if step > 0 {
+		n := (f - min) / step
+		if math.Abs(n-math.Round(n)) > 1e-3 {
+			return fmt.Errorf("%w: %v not a step of %v from %v", ErrInvalidValue, v, step, min)
+		}
+	}
+
+	return w.setValue(v)
+}
+
+// Int returns the value of a toggle widget, 1 if on, 0 if off.
+// Some drivers report 2 when the state is unknown.
+func (w *CameraWidget) Int() (int, error) {
+	if err := w.checkType(false, WidgetToggle); err != nil {
+		return 0, err
+	}
+
+	var val C.int
+	if err := cameraResultToError(C.gp_widget_get_value(w.widget, unsafe.Pointer(&val))); err != nil {
+		return 0, err
+	}
+	return int(val), nil
+}
+
+// SetInt sets the value of a toggle widget to 1 (on) or 0 (off).
+func (w *CameraWidget) SetInt(v int) error {
+	if err := w.checkType(true, WidgetToggle); err != nil {
+		return err
+	}
+	if v != 0 && v != 1 {
+		return fmt.Errorf("%w: toggle value %d not 0 or 1", ErrInvalidValue, v)
+	}
+
+	return w.setValue(v)
+}
+
+// Time returns the value of a date widget.
+func (w *CameraWidget) Time() (time.Time, error) {
+	if err := w.checkType(false, WidgetDate); err != nil {
+		return time.Time{}, err
+	}
+
+	var val C.int
+	if err := cameraResultToError(C.gp_widget_get_value(w.widget, unsafe.Pointer(&val))); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(val), 0), nil
+}
+
+// SetTime sets the value of a date widget, which holds whole seconds
+// since the unix epoch in a 32 bit integer.
+func (w *CameraWidget) SetTime(t time.Time) error {
+	if err := w.checkType(true, WidgetDate); err != nil {
+		return err
+	}
+	if t.Unix() < math.MinInt32 || t.Unix() > math.MaxInt32 {
+		return fmt.Errorf("%w: %v out of range", ErrInvalidValue, t)
+	}
+
+	return w.setValue(t)
+}
+
+// setValue sets a value as returned by Value without validating it,
+// e.g. to put back a value the camera reported.
+func (w *CameraWidget) setValue(v interface{}) error {
+	var ptr unsafe.Pointer
+	switch v := v.(type) {
+	case string:
+		cstr := C.CString(v)
+		defer C.free(unsafe.Pointer(cstr))
+		ptr = unsafe.Pointer(cstr)
+	case float64:
+		val := C.float(v)
+		ptr = unsafe.Pointer(&val)
+	case int:
+		val := C.int(v)
+		ptr = unsafe.Pointer(&val)
+	case time.Time:
+		val := C.int(v.Unix())
+		ptr = unsafe.Pointer(&val)
+	default:
+		return fmt.Errorf("%w: %T", ErrInvalidValue, v)
+	}
+
+	return cameraResultToError(C.gp_widget_set_value(w.widget, ptr))
+}
+
 // Free func
 func (w *CameraWidget) Free() {
 	if err := cameraResultToError(C.gp_widget_free(w.widget)); err != nil {
@@ -82,7 +368,6 @@ func (w *CameraWidget) Free() {
 // Name func
 func (w *CameraWidget) Name() (string, error) {
 	var _name *C.char
-	defer C.free(unsafe.Pointer(_name))
 
 	if err := cameraResultToError(C.gp_widget_get_name(w.widget, &_name)); err != nil {
 		return "", err
@@ -91,9 +376,10 @@ func (w *CameraWidget) Name() (string, error) {
 	return ToString(_name), nil
 }
 
-// Value func
+// Value returns the value of the widget: a string for text, radio and menu widgets,
+// a float64 for ranges, an int for toggles, a time.Time for dates
+// and an empty string for widgets without a value.
 func (w *CameraWidget) Value() (interface{}, error) {
-	var err error
 	wti, err := w.Type()
 	if err != nil {
 		return nil, err
@@ -101,30 +387,16 @@ func (w *CameraWidget) Value() (interface{}, error) {
 
 	switch wti.vtype {
 	case wvtString:
-		var val *C.char
-		defer C.free(unsafe.Pointer(val)) // copied by ToString()
-		if err := cameraResultToError(C.gp_widget_get_value(w.widget, unsafe.Pointer(&val))); err != nil {
-			return nil, err
-		}
-		return ToString(val), nil
+		return w.String()
 	case wvtNum:
-		var val = new(int)
-		if err := cameraResultToError(C.gp_widget_get_value(w.widget, unsafe.Pointer(val))); err != nil {
-			return nil, err
-		}
-		return *val, nil
+		return w.Int()
+	case wvtFloat:
+		return w.Float()
 	case wvtDate:
-		var val = new(int64)
-		if err := cameraResultToError(C.gp_widget_get_value(w.widget, unsafe.Pointer(val))); err != nil {
-			return nil, err
-		}
-		return time.Unix(*val, 0), nil
-	case wvtWeird:
-	default:
-		return "", nil
+		return w.Time()
 	}
 
-	return nil, err
+	return "", nil
 }
 
 // Parent func
@@ -141,7 +413,6 @@ func (w *CameraWidget) Parent() (*CameraWidget, error) {
 // Label func
 func (w *CameraWidget) Label() (string, error) {
 	var _label *C.char
-	defer C.free(unsafe.Pointer(_label))
 
 	if err := cameraResultToError(C.gp_widget_get_label(w.widget, &_label)); err != nil {
 		return "", err
@@ -183,7 +454,21 @@ func (w *CameraWidget) Child(name string) (*CameraWidget, error) {
 	err := cameraResultToError(C.gp_widget_get_child_by_name(w.widget, n, &child))
 	if err != nil {
 		C.free(unsafe.Pointer(child))
-		return nil, fmt.Errorf("error on C.gp_widget_get_child_by_name(%s): %v", name, err)
+		return nil, fmt.Errorf("error on C.gp_widget_get_child_by_name(%s): %w", name, err)
+	}
+
+	return &CameraWidget{child}, nil
+}
+
+// ChildByLabel finds a descendant by its label, e.g. "ISO Speed"
+func (w *CameraWidget) ChildByLabel(label string) (*CameraWidget, error) {
+	var child *C.CameraWidget
+
+	l := C.CString(label)
+	defer C.free(unsafe.Pointer(l))
+
+	if err := cameraResultToError(C.gp_widget_get_child_by_label(w.widget, l, &child)); err != nil {
+		return nil, fmt.Errorf("error on C.gp_widget_get_child_by_label(%s): %w", label, err)
 	}
 
 	return &CameraWidget{child}, nil
@@ -200,6 +485,8 @@ func (w *CameraWidget) ValueType() (string, error) {
 		return "string", nil
 	case wvtNum:
 		return "int", nil
+	case wvtFloat:
+		return "float", nil
 	case wvtDate:
 		return "date", nil
 	default:
@@ -208,11 +495,10 @@ func (w *CameraWidget) ValueType() (string, error) {
 }
 
 func (w *CameraWidget) choiceCount() (int, error) {
-	if tipe, err := w.Type(); err == nil {
-		if tipe.enum != C.GP_WIDGET_RADIO {
+	if err := w.checkType(false, WidgetRadio, WidgetMenu); err != nil {
+		if errors.Is(err, ErrInvalidValue) {
 			return 0, nil
 		}
-	} else {
 		return 0, err
 	}
 	numChoices := C.gp_widget_count_choices(w.widget)
@@ -222,7 +508,7 @@ func (w *CameraWidget) choiceCount() (int, error) {
 	return int(numChoices), nil
 }
 
-// Choices func
+// Choices returns the choices of a radio or menu widget, none for other widgets.
 func (w *CameraWidget) Choices() ([]string, error) {
 	numChoices, err := w.choiceCount()
 	if err != nil {
@@ -238,3 +524,58 @@ func (w *CameraWidget) Choices() ([]string, error) {
 	}
 	return choices, nil
 }
+
+// Choice returns the index of the current value of a radio or menu widget in its Choices,
+// -1 if the camera reports a value that is not one of them.
+func (w *CameraWidget) Choice() (int, error) {
+	v, err := w.String()
+	if err != nil {
+		return -1, err
+	}
+	choices, err := w.Choices()
+	if err != nil {
+		return -1, err
+	}
+
+	for i, c := range choices {
+		if c == v {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+// SetChoice sets a radio or menu widget to the choice at index.
+func (w *CameraWidget) SetChoice(index int) error {
+	if err := w.checkType(true, WidgetRadio, WidgetMenu); err != nil {
+		return err
+	}
+	choices, err := w.Choices()
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(choices) {
+		return fmt.Errorf("%w: choice %d not in [0, %d)", ErrInvalidValue, index, len(choices))
+	}
+
+	return w.SetString(choices[index])
+}
+
+// SetChoiceValue sets a radio or menu widget to value, which must be one of its Choices.
+// SetString passes any value on to the driver instead.
+func (w *CameraWidget) SetChoiceValue(value string) error {
+	if err := w.checkType(true, WidgetRadio, WidgetMenu); err != nil {
+		return err
+	}
+	choices, err := w.Choices()
+	if err != nil {
+		return err
+	}
+	for _, c := range choices {
+		if c == value {
+			return w.SetString(value)
+		}
+	}
+
+	return fmt.Errorf("%w: %q not one of %q", ErrInvalidValue, value, choices)
+}
@@ -0,0 +1,148 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+)
+
+const defaultCaptureTimeout = 5 * time.Second
+
+// CaptureOptions configure CaptureAll.
+type CaptureOptions struct {
+	// Timeout is the longest to wait for the files following the first one,
+	// 5 seconds if zero. Waiting stops early on an EventCaptureComplete.
+	Timeout time.Duration
+	// Idle stops waiting once no event was received for this long.
+	// Zero waits for an EventCaptureComplete or the Timeout.
+	Idle time.Duration
+	// Download stores the contents of every file in CapturedFile.Data.
+	Download bool
+	// Writer, if set, is called for every file and the file is downloaded
+	// to the returned io.Writer instead of into CapturedFile.Data.
+	Writer func(CameraFilePath) (io.Writer, error)
+}
+
+// CapturedFile is a file produced by CaptureAll.
+type CapturedFile struct {
+	CameraFilePath
+	// Data holds the contents if CaptureOptions.Download was set.
+	Data []byte
+	// Size is the number of bytes downloaded.
+	Size int64
+}
+
+type contextCapturer interface {
+	TriggerCaptureToFileContext(ctx context.Context) (CameraFilePath, error)
+}
+
+type contextEventWaiter interface {
+	WaitForEventContext(ctx context.Context, timeout int) (*CameraEvent, error)
+}
+
+func backendWait(b Backend) eventWaitFunc {
+	if w, ok := b.(contextEventWaiter); ok {
+		return w.WaitForEventContext
+	}
+	return func(_ context.Context, timeout int) (*CameraEvent, error) {
+		return b.WaitForEvent(timeout)
+	}
+}
+
+// CaptureAll captures an image and returns every file it produced.
+// TriggerCaptureToFile only returns the first file, e.g. the RAW of a RAW+JPEG shot,
+// the others are reported by the camera as EventFileAdded events afterwards.
+// Unrelated events received meanwhile are dropped.
+func CaptureAll(ctx context.Context, b Backend, opts CaptureOptions) ([]CapturedFile, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultCaptureTimeout
+	}
+
+	var first CameraFilePath
+	var err error
+	if c, ok := b.(contextCapturer); ok {
+		first, err = c.TriggerCaptureToFileContext(ctx)
+	} else {
+		first, err = b.TriggerCaptureToFile()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := []CapturedFile{{CameraFilePath: first}}
+	seen := map[CameraFilePath]bool{first: true}
+
+	wait := backendWait(b)
+	deadline := time.Now().Add(opts.Timeout)
+	last := time.Now()
+	for {
+		now := time.Now()
+		if !now.Before(deadline) || (opts.Idle > 0 && now.Sub(last) >= opts.Idle) {
+			break
+		}
+
+		timeout := deadline.Sub(now)
+		if timeout > eventPoll {
+			timeout = eventPoll
+		}
+
+		ev, err := wait(ctx, int(timeout/time.Millisecond)+1)
+		if err != nil {
+			return files, err
+		}
+		if ev.Type == EventTimeout {
+			if err := ctx.Err(); err != nil {
+				return files, err
+			}
+			continue
+		}
+		last = time.Now()
+
+		if ev.Type == EventCaptureComplete {
+			break
+		}
+		if ev.Type != EventFileAdded {
+			continue
+		}
+
+		p := CameraFilePath{Folder: ev.Folder, Name: ev.File}
+		if !seen[p] {
+			seen[p] = true
+			files = append(files, CapturedFile{CameraFilePath: p})
+		}
+	}
+
+	if !opts.Download && opts.Writer == nil {
+		return files, nil
+	}
+
+	for i := range files {
+		if err := downloadCaptured(ctx, b, &files[i], opts); err != nil {
+			return files, err
+		}
+	}
+
+	return files, nil
+}
+
+func downloadCaptured(ctx context.Context, b Backend, f *CapturedFile, opts CaptureOptions) error {
+	var w io.Writer
+	var buf *bytes.Buffer
+	var err error
+	if opts.Writer != nil {
+		if w, err = opts.Writer(f.CameraFilePath); err != nil {
+			return err
+		}
+	} else {
+		buf = new(bytes.Buffer)
+		w = buf
+	}
+
+	f.Size, err = download(ctx, b, f.Folder, f.Name, w)
+	if buf != nil {
+		f.Data = buf.Bytes()
+	}
+
+	return err
+}
@@ -0,0 +1,111 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCaptureAll(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCapture([]byte("data"), ".CR2", ".JPG")
+
+	files, err := CaptureAll(context.Background(), f, CaptureOptions{Timeout: time.Second, Download: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name)
+		if string(file.Data) != "data" || file.Size != 4 {
+			t.Errorf("expected %s to be downloaded, got %q (%d)", file.Name, file.Data, file.Size)
+		}
+	}
+	if exp := []string{"IMG_0001.CR2", "IMG_0001.JPG"}; !reflect.DeepEqual(names, exp) {
+		t.Errorf("expected files %v, got %v", exp, names)
+	}
+}
+
+func TestCaptureAllWriter(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCapture([]byte("data"), ".CR2", ".JPG")
+
+	bufs := make(map[string]*bytes.Buffer)
+	opts := CaptureOptions{
+		Timeout: time.Second,
+		Writer: func(p CameraFilePath) (io.Writer, error) {
+			bufs[p.Name] = new(bytes.Buffer)
+			return bufs[p.Name], nil
+		},
+	}
+	files, err := CaptureAll(context.Background(), f, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || len(bufs) != 2 {
+		t.Fatalf("expected 2 files, got %d, %d written", len(files), len(bufs))
+	}
+	for _, file := range files {
+		if file.Data != nil {
+			t.Errorf("expected no data in memory for %s", file.Name)
+		}
+		if bufs[file.Name].String() != "data" {
+			t.Errorf("expected %s to be written, got %q", file.Name, bufs[file.Name])
+		}
+	}
+}
+
+// incompleteFake drops EventCaptureComplete like drivers that never send it.
+type incompleteFake struct {
+	*Fake
+}
+
+func (f incompleteFake) WaitForEvent(timeout int) (*CameraEvent, error) {
+	ev, err := f.Fake.WaitForEvent(timeout)
+	if err == nil && ev.Type == EventCaptureComplete {
+		ev = &CameraEvent{Type: EventTimeout}
+	}
+	return ev, err
+}
+
+func TestCaptureAllTimeout(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCapture([]byte("data"), ".CR2", ".JPG")
+
+	start := time.Now()
+	files, err := CaptureAll(context.Background(), incompleteFake{f}, CaptureOptions{Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected 2 files, got %+v", files)
+	}
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Errorf("expected to wait for the timeout, returned after %s", d)
+	}
+
+	start = time.Now()
+	files, err = CaptureAll(context.Background(), incompleteFake{f}, CaptureOptions{Timeout: time.Minute, Idle: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Name != "IMG_0002.CR2" {
+		t.Errorf("expected the files of the second capture, got %+v", files)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("expected to stop when idle, returned after %s", d)
+	}
+}
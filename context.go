@@ -0,0 +1,136 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdint.h>
+//
+// extern GPContextFeedback gphoto2goCancel(GPContext *context, void *data);
+// extern unsigned int gphoto2goProgressStart(GPContext *context, float target, char *text, void *data);
+// extern void gphoto2goProgressUpdate(GPContext *context, unsigned int id, float current, void *data);
+// extern void gphoto2goProgressStop(GPContext *context, unsigned int id, void *data);
+// extern void gphoto2goContextError(GPContext *context, char *text, void *data);
+// extern void gphoto2goContextStatus(GPContext *context, char *text, void *data);
+// extern void gphoto2goContextMessage(GPContext *context, char *text, void *data);
+//
+// static void gphoto2go_context_setup(GPContext *context, uintptr_t handle) {
+// 	gp_context_set_cancel_func(context, gphoto2goCancel, (void *)handle);
+// 	gp_context_set_progress_funcs(
+// 		context,
+// 		(GPContextProgressStartFunc)gphoto2goProgressStart,
+// 		gphoto2goProgressUpdate,
+// 		gphoto2goProgressStop,
+// 		(void *)handle
+// 	);
+// 	gp_context_set_error_func(context, (GPContextErrorFunc)gphoto2goContextError, (void *)handle);
+// 	gp_context_set_status_func(context, (GPContextStatusFunc)gphoto2goContextStatus, (void *)handle);
+// 	gp_context_set_message_func(context, (GPContextMessageFunc)gphoto2goContextMessage, (void *)handle);
+// }
+import "C"
+import (
+	"context"
+	"runtime/cgo"
+	"sync/atomic"
+)
+
+// newContext creates the GPContext of c and registers the callbacks
+// libgphoto2 uses to report back to c.
+func (c *Camera) newContext() {
+	if c.handle == 0 {
+		c.handle = cgo.NewHandle(c)
+	}
+	c.context = C.gp_context_new()
+	C.gphoto2go_context_setup(c.context, C.uintptr_t(c.handle))
+}
+
+// call runs fn, which calls into libgphoto2 using c.context, on behalf of ctx.
+// fn is run on the operation queue of c at PriorityNormal.
+// Drivers poll the cancel function of c.context during long transfers,
+// which aborts fn as soon as ctx is done or Cancel is called.
+func (c *Camera) call(ctx context.Context, fn func() C.int) error {
+	return c.callPriority(ctx, PriorityNormal, fn)
+}
+
+// callPriority is call at priority p.
+func (c *Camera) callPriority(ctx context.Context, p Priority, fn func() C.int) error {
+	return c.run(ctx, p, "", false, fn)
+}
+
+// callFile is callPriority for a transfer of the file at path,
+// which is passed on to the ProgressFunc.
+func (c *Camera) callFile(ctx context.Context, p Priority, path string, fn func() C.int) error {
+	return c.run(ctx, p, path, false, fn)
+}
+
+// callChunk is callPriority for a part of a transfer whose progress gphoto2go reports itself,
+// the progress reported by the driver is dropped.
+func (c *Camera) callChunk(ctx context.Context, p Priority, fn func() C.int) error {
+	return c.run(ctx, p, "", true, fn)
+}
+
+func (c *Camera) run(ctx context.Context, p Priority, path string, quiet bool, fn func() C.int) error {
+	return c.exec(ctx, p, path, quiet, func() int { return int(fn()) })
+}
+
+// exec runs fn on the queue and turns the libgphoto2 result it returns into an error.
+// fn is skipped if Cancel is called while it is queued.
+// Progress reported by the driver is passed on with path, or dropped if quiet.
+func (c *Camera) exec(ctx context.Context, p Priority, path string, quiet bool, fn func() int) error {
+	if err := ctx.Err(); err != nil {
+		return contextError(err)
+	}
+
+	gen := atomic.LoadInt32(&c.cancelGen)
+	var ret int
+	err := c.queue.do(ctx, p, func() {
+		if atomic.LoadInt32(&c.cancelGen) != gen {
+			ret = C.GP_ERROR_CANCEL
+			return
+		}
+
+		c.ctxMu.Lock()
+		c.ctx = ctx
+		c.gen = gen
+		c.path = path
+		c.quiet = quiet
+		c.ctxMu.Unlock()
+
+		ret = fn()
+
+		c.ctxMu.Lock()
+		c.ctx = nil
+		c.path = ""
+		c.quiet = false
+		c.ctxMu.Unlock()
+	})
+	if err != nil {
+		return contextError(err)
+	}
+
+	if ret == C.GP_ERROR_CANCEL && ctx.Err() != nil {
+		return contextError(ctx.Err())
+	}
+
+	return cameraResultToError(C.int(ret))
+}
+
+// cancelRequested reports whether the running operation should be aborted.
+func (c *Camera) cancelRequested() bool {
+	c.ctxMu.Lock()
+	ctx, gen := c.ctx, c.gen
+	c.ctxMu.Unlock()
+
+	if ctx == nil {
+		return false
+	}
+
+	return atomic.LoadInt32(&c.cancelGen) != gen || ctx.Err() != nil
+}
+
+// contextError wraps err as an ErrCancel *Error.
+func contextError(err error) error {
+	return &Error{
+		code:    ErrCancel,
+		message: CameraResultToString(C.GP_ERROR_CANCEL),
+		err:     err,
+	}
+}
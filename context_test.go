@@ -0,0 +1,98 @@
+package gphoto2go
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCallWithDoneContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A done context never reaches libgphoto2, so no camera is needed.
+	c := new(Camera)
+	_, err := c.ListFilesContext(ctx, "/")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	var gpErr *Error
+	if !errors.As(err, &gpErr) || !gpErr.Is(ErrCancel) {
+		t.Fatalf("expected an ErrCancel *Error, got %v", err)
+	}
+}
+
+func TestCancelQueued(t *testing.T) {
+	c := new(Camera)
+	defer c.queue.stop()
+
+	release, started := make(chan struct{}), make(chan struct{})
+	go c.exec(context.Background(), PriorityNormal, "", false, func() int {
+		close(started)
+		<-release
+		return 0
+	})
+	<-started
+
+	ran := false
+	done := make(chan error)
+	go func() {
+		done <- c.exec(context.Background(), PriorityNormal, "", false, func() int {
+			ran = true
+			return 0
+		})
+	}()
+	waitQueued(t, &c.queue, 1)
+
+	c.Cancel()
+	close(release)
+	if err := <-done; !isCode(err, ErrCancel) {
+		t.Errorf("expected ErrCancel, got %v", err)
+	}
+	if ran {
+		t.Error("expected the queued operation to be skipped")
+	}
+
+	if err := c.exec(context.Background(), PriorityNormal, "", false, func() int { return 0 }); err != nil {
+		t.Errorf("expected operations after Cancel to run, got %v", err)
+	}
+}
+
+func TestCancelNotSticky(t *testing.T) {
+	c := new(Camera)
+	c.fail(contextError(context.Canceled))
+	if c.err != nil {
+		t.Errorf("expected a cancellation not to be recorded, got %v", c.err)
+	}
+	c.fail(newError(ErrIO))
+	if !isCode(c.err, ErrIO) {
+		t.Errorf("expected ErrIO to be recorded, got %v", c.err)
+	}
+}
+
+func TestInitAfterFailure(t *testing.T) {
+	c := new(Camera)
+	// As left by an Init that failed.
+	c.fail(newError(ErrIO))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.InitContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if err := c.initErr(); err != nil {
+		t.Errorf("expected Init to clear the previous error, got %v", err)
+	}
+	if c.camera != nil || c.handle != 0 {
+		t.Error("expected the failed Init to release the camera")
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("expected closing a released camera to do nothing, got %v", err)
+	}
+
+	var e *Error
+	if err := c.SetConfigContext(ctx); !errors.Is(err, context.Canceled) || !errors.As(err, &e) {
+		t.Errorf("expected SetConfig to wrap the cancellation, got %v", err)
+	}
+}
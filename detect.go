@@ -0,0 +1,172 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"context"
+	"strings"
+	"unsafe"
+)
+
+// directoryBrowseModel is the model name of libgphoto2's directory driver,
+// which serves a local folder through a "disk:/path" port.
+const directoryBrowseModel = "Directory Browse"
+
+// DetectedCamera is a camera found by Detect.
+type DetectedCamera struct {
+	Model string
+	Port  string
+}
+
+// Detect lists all connected cameras as model and port pairs
+// which can be passed to Open.
+func Detect() ([]DetectedCamera, error) {
+	ctx := C.gp_context_new()
+	defer C.gp_context_unref(ctx)
+
+	var list *C.CameraList
+	if err := cameraResultToError(C.gp_list_new(&list)); err != nil {
+		return nil, err
+	}
+	defer C.gp_list_free(list)
+
+	if ret := C.gp_camera_autodetect(list, ctx); ret < C.GP_OK {
+		return nil, cameraResultToError(ret)
+	}
+
+	size := int(C.gp_list_count(list))
+	if size < 0 {
+		return nil, cameraResultToError(C.int(size))
+	}
+
+	cams := make([]DetectedCamera, 0, size)
+	for i := 0; i < size; i++ {
+		var cModel, cPort *C.char
+		if err := cameraResultToError(C.gp_list_get_name(list, C.int(i), &cModel)); err != nil {
+			return nil, err
+		}
+		if err := cameraResultToError(C.gp_list_get_value(list, C.int(i), &cPort)); err != nil {
+			return nil, err
+		}
+		cams = append(cams, DetectedCamera{Model: C.GoString(cModel), Port: C.GoString(cPort)})
+	}
+
+	return cams, nil
+}
+
+// Open creates and initializes the camera of the given model on the given port,
+// e.g. as returned by Detect.
+// An empty model lets libgphoto2 probe the port and an empty port
+// uses the first port the model is found on.
+// A "disk:/path" port without a model opens /path with the Directory Browse driver.
+func Open(model, port string) (*Camera, error) {
+	return OpenContext(context.Background(), model, port)
+}
+
+// OpenContext is Open with a context.
+func OpenContext(ctx context.Context, model, port string) (*Camera, error) {
+	if model == "" && strings.HasPrefix(port, "disk:") {
+		model = directoryBrowseModel
+	}
+
+	c := new(Camera)
+	c.newContext()
+	C.gp_camera_new(&c.camera)
+
+	if model != "" {
+		if err := c.setModel(model); err != nil {
+			c.free()
+			return nil, err
+		}
+	}
+	if port != "" {
+		if err := c.setPort(port); err != nil {
+			c.free()
+			return nil, err
+		}
+	}
+
+	if err := c.init(ctx); err != nil {
+		c.free()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Camera) setModel(model string) error {
+	var list *C.CameraAbilitiesList
+	if err := cameraResultToError(C.gp_abilities_list_new(&list)); err != nil {
+		return err
+	}
+	defer C.gp_abilities_list_free(list)
+
+	if err := cameraResultToError(C.gp_abilities_list_load(list, c.context)); err != nil {
+		return err
+	}
+
+	cModel := C.CString(model)
+	defer C.free(unsafe.Pointer(cModel))
+
+	ix := C.gp_abilities_list_lookup_model(list, cModel)
+	if ix < C.GP_OK {
+		return cameraResultToError(ix)
+	}
+
+	var abilities C.CameraAbilities
+	if err := cameraResultToError(C.gp_abilities_list_get_abilities(list, ix, &abilities)); err != nil {
+		return err
+	}
+
+	return cameraResultToError(C.gp_camera_set_abilities(c.camera, abilities))
+}
+
+func (c *Camera) setPort(port string) error {
+	var list *C.GPPortInfoList
+	if err := cameraResultToError(C.gp_port_info_list_new(&list)); err != nil {
+		return err
+	}
+	defer C.gp_port_info_list_free(list)
+
+	if ret := C.gp_port_info_list_load(list); ret < C.GP_OK {
+		return cameraResultToError(ret)
+	}
+
+	cPort := C.CString(port)
+	defer C.free(unsafe.Pointer(cPort))
+
+	// Generic entries such as "disk:" match any path and are
+	// appended to the list as a new entry for that path.
+	ix := C.gp_port_info_list_lookup_path(list, cPort)
+	if ix < C.GP_OK {
+		return cameraResultToError(ix)
+	}
+
+	var info C.GPPortInfo
+	if err := cameraResultToError(C.gp_port_info_list_get_info(list, ix, &info)); err != nil {
+		return err
+	}
+
+	// gp_camera_set_port_info copies info, so the list can be freed afterwards.
+	return cameraResultToError(C.gp_camera_set_port_info(c.camera, info))
+}
+
+func (c *Camera) free() {
+	c.queue.stop()
+	c.mu.Lock()
+	if c.config != nil {
+		C.gp_widget_free(c.config)
+		c.config = nil
+	}
+	c.mu.Unlock()
+	C.gp_camera_unref(c.camera)
+	C.gp_context_unref(c.context)
+	if c.handle != 0 {
+		c.handle.Delete()
+	}
+	c.camera = nil
+	c.context = nil
+	c.handle = 0
+}
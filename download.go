@@ -0,0 +1,228 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdint.h>
+// #include <stdlib.h>
+//
+// extern int gphoto2goFileSize(void *priv, uint64_t *size);
+// extern int gphoto2goFileRead(void *priv, unsigned char *data, uint64_t *len);
+// extern int gphoto2goFileWrite(void *priv, unsigned char *data, uint64_t *len);
+//
+// static CameraFileHandler gphoto2go_file_handler = {
+// 	gphoto2goFileSize,
+// 	gphoto2goFileRead,
+// 	gphoto2goFileWrite,
+// };
+//
+// static int gphoto2go_file_new(CameraFile **file, uintptr_t handle) {
+// 	return gp_file_new_from_handler(file, &gphoto2go_file_handler, (void *)handle);
+// }
+import "C"
+import (
+	"context"
+	"io"
+	"path"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// downloadChunk is the size of the partial reads of DownloadTo,
+// operations of a higher priority run in between them.
+const downloadChunk = 4 * 1024 * 1024
+
+// fileHandler backs a CameraFile created with gp_file_new_from_handler,
+// the data the driver appends to it is written to w as it arrives.
+// When uploading, the driver reads the size bytes of r instead.
+type fileHandler struct {
+	w    io.Writer
+	r    io.Reader
+	size int64
+	n    int64
+	err  error
+}
+
+// newHandlerFile creates a CameraFile backed by h, free it before deleting the handle.
+func newHandlerFile(h *fileHandler) (*C.CameraFile, cgo.Handle, error) {
+	handle := cgo.NewHandle(h)
+	var cFile *C.CameraFile
+	if err := cameraResultToError(C.gphoto2go_file_new(&cFile, C.uintptr_t(handle))); err != nil {
+		handle.Delete()
+		return nil, 0, err
+	}
+
+	return cFile, handle, nil
+}
+
+func (h *fileHandler) write(p []byte) C.int {
+	n, err := h.w.Write(p)
+	h.n += int64(n)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		h.err = err
+		return C.GP_ERROR_IO
+	}
+
+	return C.GP_OK
+}
+
+// read fills p from r, a file ending before size bytes is an error.
+func (h *fileHandler) read(p []byte) (int, C.int) {
+	n, err := io.ReadFull(h.r, p)
+	h.n += int64(n)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+		if h.n < h.size {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+	if err != nil {
+		h.err = err
+		return n, C.GP_ERROR_IO
+	}
+
+	return n, C.GP_OK
+}
+
+// DownloadTo streams a file to w while it is being downloaded,
+// instead of buffering all of it in memory like FileReader does.
+// It returns the number of bytes written.
+//
+// Drivers supporting partial reads download the file in chunks, each a separate operation,
+// so operations of a higher priority, like CapturePreview, do not wait for the whole file.
+// Others download it in one go, see Open on how support is found out.
+func (c *Camera) DownloadTo(folder, file string, w io.Writer) (int64, error) {
+	return c.DownloadToContext(context.Background(), folder, file, w)
+}
+
+// DownloadToContext is DownloadTo with a context and options, see WithFileType to download thumbnails and such.
+func (c *Camera) DownloadToContext(ctx context.Context, folder, file string, w io.Writer, opts ...FileOption) (int64, error) {
+	o := newFileOptions(opts)
+	support := c.readSupport(o.typ)
+	if known, supported := support.get(); !known || supported {
+		n, err := c.downloadChunked(ctx, folder, file, o, w)
+		if n != 0 || !isCode(err, ErrNotSupported) {
+			if err == nil {
+				support.set(true)
+			}
+			return n, err
+		}
+		support.set(false)
+	}
+
+	return c.downloadWhole(ctx, folder, file, o, w)
+}
+
+// downloadChunked downloads a file with partial reads, reporting its progress itself.
+func (c *Camera) downloadChunked(ctx context.Context, folder, file string, o fileOptions, w io.Writer) (int64, error) {
+	src := newCameraSource(c, folder, file, o)
+	src.quiet = true
+	defer src.close()
+
+	return c.downloadSource(ctx, src, folder, file, w)
+}
+
+// downloadSource writes src to w in chunks, reporting the progress in bytes.
+func (c *Camera) downloadSource(ctx context.Context, src fileSource, folder, file string, w io.Writer) (int64, error) {
+	p := Progress{Path: path.Join(folder, file), Text: "Downloading " + file}
+	n, err := writeChunks(ctx, src, w, make([]byte, downloadChunk), func(done, total int64) {
+		p.Done, p.Total = done, total
+		c.reportProgress(p)
+	})
+	if n != 0 {
+		p.Done, p.Finished = n, true
+		c.reportProgress(p)
+	}
+
+	return n, err
+}
+
+// writeChunks writes src to w with reads of at most len(buf) bytes,
+// calling progress after each with the bytes written so far and the size, 0 if unknown.
+func writeChunks(ctx context.Context, src fileSource, w io.Writer, buf []byte, progress func(done, total int64)) (int64, error) {
+	size, err := src.size(ctx)
+	if err != nil {
+		size = -1
+	}
+
+	var n int64
+	for size < 0 || n < size {
+		p := buf
+		if size >= 0 && size-n < int64(len(p)) {
+			p = p[:size-n]
+		}
+
+		m, err := src.readAt(ctx, p, n)
+		if err != nil {
+			return n, err
+		}
+		if m == 0 {
+			break
+		}
+
+		written, err := w.Write(p[:m])
+		n += int64(written)
+		if err == nil && written < m {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			return n, err
+		}
+
+		progress(n, max(size, 0))
+	}
+
+	return n, nil
+}
+
+// downloadWhole downloads a file with a single gp_camera_file_get.
+func (c *Camera) downloadWhole(ctx context.Context, folder, file string, o fileOptions, w io.Writer) (int64, error) {
+	h := &fileHandler{w: w}
+	cFile, handle, err := newHandlerFile(h)
+	if err != nil {
+		return 0, err
+	}
+	defer handle.Delete()
+	defer C.gp_file_free(cFile)
+
+	cFolder := C.CString(folder)
+	cName := C.CString(file)
+	defer C.free(unsafe.Pointer(cFolder))
+	defer C.free(unsafe.Pointer(cName))
+
+	cType := C.CameraFileType(o.typ)
+	err = c.callFile(ctx, o.priority, path.Join(folder, file), func() C.int {
+		return C.gp_camera_file_get(c.camera, cFolder, cName, cType, cFile, c.context)
+	})
+	if h.err != nil {
+		return h.n, h.err
+	}
+
+	return h.n, err
+}
+
+type contextDownloader interface {
+	DownloadToContext(ctx context.Context, folder, file string, w io.Writer, opts ...FileOption) (int64, error)
+}
+
+// download writes a file of b to w, streaming it if b supports it.
+func download(ctx context.Context, b Backend, folder, file string, w io.Writer) (int64, error) {
+	if d, ok := b.(contextDownloader); ok {
+		return d.DownloadToContext(ctx, folder, file, w)
+	}
+
+	var r io.ReadCloser
+	if cr, ok := b.(contextFileReader); ok {
+		var err error
+		if r, err = cr.FileReaderContext(ctx, folder, file); err != nil {
+			return 0, err
+		}
+	} else {
+		r = b.FileReader(folder, file)
+	}
+	defer r.Close()
+
+	return io.Copy(w, r)
+}
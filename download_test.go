@@ -0,0 +1,162 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.w.Write(p)
+	l.n -= n
+	return n, err
+}
+
+func TestFileHandlerWrite(t *testing.T) {
+	buf := new(bytes.Buffer)
+	h := &fileHandler{w: buf}
+	if ret := h.write([]byte("abc")); ret != 0 {
+		t.Fatalf("expected GP_OK, got %d", ret)
+	}
+	if ret := h.write([]byte("def")); ret != 0 {
+		t.Fatalf("expected GP_OK, got %d", ret)
+	}
+	if buf.String() != "abcdef" || h.n != 6 {
+		t.Errorf("expected abcdef (6), got %q (%d)", buf, h.n)
+	}
+
+	h = &fileHandler{w: &limitedWriter{w: new(bytes.Buffer), n: 2}}
+	if ret := h.write([]byte("abc")); ret != ErrIO {
+		t.Errorf("expected ErrIO, got %d", ret)
+	}
+	if !errors.Is(h.err, io.ErrShortWrite) || h.n != 2 {
+		t.Errorf("expected a short write of 2 bytes, got %v (%d)", h.err, h.n)
+	}
+}
+
+func TestFileHandlerRead(t *testing.T) {
+	h := &fileHandler{r: strings.NewReader("abcdef"), size: 6}
+	p := make([]byte, 4)
+	if n, ret := h.read(p); ret != 0 || string(p[:n]) != "abcd" {
+		t.Fatalf("expected abcd, got %q (%d)", p[:n], ret)
+	}
+	if n, ret := h.read(p); ret != 0 || string(p[:n]) != "ef" {
+		t.Fatalf("expected ef, got %q (%d)", p[:n], ret)
+	}
+	if n, ret := h.read(p); ret != 0 || n != 0 {
+		t.Errorf("expected an empty read at the end, got %d (%d)", n, ret)
+	}
+
+	h = &fileHandler{r: strings.NewReader("abc"), size: 6}
+	if _, ret := h.read(p); ret != ErrIO || !errors.Is(h.err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected ErrIO for a short file, got %d %v", ret, h.err)
+	}
+}
+
+func TestDownloadFallback(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFile("/store_00010001/DCIM/100FAKE", "MVI_0001.MP4", []byte("video"))
+
+	buf := new(bytes.Buffer)
+	n, err := download(context.Background(), f, "/store_00010001/DCIM/100FAKE", "MVI_0001.MP4", buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 || buf.String() != "video" {
+		t.Errorf("expected video (5), got %q (%d)", buf, n)
+	}
+}
+
+func TestWriteChunks(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFile("/DCIM", "MVI_0001.MP4", []byte("0123456789"))
+	src := &fakeSource{f: f, folder: "/DCIM", name: "MVI_0001.MP4"}
+
+	var reports [][2]int64
+	buf := new(bytes.Buffer)
+	n, err := writeChunks(context.Background(), src, buf, make([]byte, 4), func(done, total int64) {
+		reports = append(reports, [2]int64{done, total})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 || buf.String() != "0123456789" {
+		t.Errorf("expected 10 bytes, got %d %q", n, buf)
+	}
+	if len(reports) != 3 || reports[2] != [2]int64{10, 10} {
+		t.Errorf("expected 3 progress reports ending at 10 of 10, got %v", reports)
+	}
+
+	f.Fail("ReadSeeker", newError(ErrNotSupported))
+	n, err = writeChunks(context.Background(), src, new(bytes.Buffer), make([]byte, 4), func(int64, int64) {})
+	if n != 0 || !isCode(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported before writing, got %d %v", n, err)
+	}
+}
+
+// driverSource reads data like a driver reporting progress of its own for every read.
+type driverSource struct {
+	c    *Camera
+	data []byte
+}
+
+func (s *driverSource) readAt(ctx context.Context, p []byte, off int64) (n int, err error) {
+	err = s.c.exec(ctx, PriorityLow, "", true, func() int {
+		id := s.c.progressStart(float64(len(p)/200), "Downloading")
+		s.c.progressUpdate(id, float64(len(p)/200))
+		s.c.progressStop(id)
+		if off < int64(len(s.data)) {
+			n = copy(p, s.data[off:])
+		}
+		return 0
+	})
+	return n, err
+}
+
+func (s *driverSource) size(context.Context) (int64, error) { return int64(len(s.data)), nil }
+func (s *driverSource) close()                              {}
+
+func TestDownloadProgress(t *testing.T) {
+	c := new(Camera)
+	defer c.queue.stop()
+	var reports []Progress
+	c.SetProgressFunc(func(p Progress) { reports = append(reports, p) })
+
+	data := make([]byte, 2*downloadChunk+100)
+	n, err := c.downloadSource(context.Background(), &driverSource{c: c, data: data}, "/DCIM", "MVI_0001.MP4", io.Discard)
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("expected %d bytes, got %d %v", len(data), n, err)
+	}
+
+	var done int64
+	finished := 0
+	for _, p := range reports {
+		if p.Path != "/DCIM/MVI_0001.MP4" || p.Total != int64(len(data)) || p.Done < done {
+			t.Errorf("expected increasing bytes of the file, got %+v", p)
+		}
+		done = p.Done
+		if p.Finished {
+			finished++
+		}
+	}
+	if finished != 1 || !reports[len(reports)-1].Finished || done != int64(len(data)) {
+		t.Errorf("expected a single final report of all bytes, got %+v", reports)
+	}
+}
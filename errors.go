@@ -3,7 +3,10 @@ package gphoto2go
 // #cgo pkg-config: libgphoto2
 // #include <gphoto2.h>
 import "C"
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 const (
 	Err                   = C.GP_ERROR
@@ -48,12 +51,23 @@ const (
 type Error struct {
 	code    int
 	message string
+	err     error
+	log     []string
 }
 
 func (e *Error) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("libgphoto2: [%d] %s: %s", e.code, e.message, e.err)
+	}
 	return fmt.Sprintf("libgphoto2: [%d] %s", e.code, e.message)
 }
 
+// Unwrap returns the underlying error if any, e.g. context.Canceled
+// for an ErrCancel caused by a done context.Context.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
 func (e *Error) Code() int {
 	return e.code
 }
@@ -62,6 +76,12 @@ func (e *Error) Message() string {
 	return e.message
 }
 
+// Log returns the libgphoto2 debug lines leading up to the error,
+// if enabled with SetLogHistory.
+func (e *Error) Log() []string {
+	return e.log
+}
+
 func (e *Error) Is(i int) bool {
 	return e.code == i
 }
@@ -76,10 +96,26 @@ func cameraResultToError(code C.int) error {
 		str = errUnknown
 	}
 
-	return &Error{
+	err := &Error{
 		code:    int(code),
 		message: str,
 	}
+	if log := logHistory(); len(log) != 0 {
+		err.log = log
+	}
+
+	return err
+}
+
+// newError returns the error for one of the Err* codes.
+func newError(code int) error {
+	return cameraResultToError(C.int(code))
+}
+
+// isCode reports whether err is or wraps an *Error with one of the Err* codes.
+func isCode(err error, code int) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Is(code)
 }
 
 // CameraResultToString func
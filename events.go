@@ -0,0 +1,106 @@
+package gphoto2go
+
+import (
+	"context"
+	"time"
+)
+
+// eventPoll is the longest a stream waits for a single event,
+// keeping the operation queue available for other operations.
+const eventPoll = 100 * time.Millisecond
+
+// EventStream delivers camera events until its context is done
+// or waiting for events fails.
+type EventStream struct {
+	// C receives the events and is closed when the stream ends.
+	C <-chan *CameraEvent
+
+	done chan struct{}
+	err  error
+}
+
+// Err blocks until the stream ended and returns why,
+// the context's error or the error returned by the driver.
+func (s *EventStream) Err() error {
+	<-s.done
+	return s.err
+}
+
+type eventWaitFunc func(ctx context.Context, timeout int) (*CameraEvent, error)
+
+type eventDecodeFunc func(ctx context.Context, ev *CameraEvent) *CameraEvent
+
+// Events streams all events of the camera until ctx is done.
+// An EventTimeout is sent whenever no other event occurred for idle,
+// a zero idle never sends one.
+// Property changes reported by PTP cameras are decoded into EventConfigChanged events.
+//
+// Events are waited for at PriorityLow, in short slices,
+// so other operations can run in between.
+func (c *Camera) Events(ctx context.Context, idle time.Duration) *EventStream {
+	wait := func(ctx context.Context, timeout int) (*CameraEvent, error) {
+		return c.waitForEvent(ctx, timeout, PriorityLow)
+	}
+
+	return streamEvents(ctx, wait, c.decodeEvent, idle)
+}
+
+// Events streams the queued events like Camera.Events.
+func (f *Fake) Events(ctx context.Context, idle time.Duration) *EventStream {
+	wait := func(_ context.Context, timeout int) (*CameraEvent, error) {
+		return f.WaitForEvent(timeout)
+	}
+	decode := func(_ context.Context, ev *CameraEvent) *CameraEvent {
+		return decodePropertyEvent(ev, f.config, nil)
+	}
+
+	return streamEvents(ctx, wait, decode, idle)
+}
+
+func streamEvents(ctx context.Context, wait eventWaitFunc, decode eventDecodeFunc, idle time.Duration) *EventStream {
+	ch := make(chan *CameraEvent)
+	s := &EventStream{C: ch, done: make(chan struct{})}
+
+	poll := eventPoll
+	if idle > 0 && idle < poll {
+		poll = idle
+	}
+
+	go func() {
+		defer close(s.done)
+		defer close(ch)
+
+		last := time.Now()
+		for {
+			ev, err := wait(ctx, int(poll/time.Millisecond))
+			if err != nil {
+				s.err = err
+				if ctx.Err() != nil {
+					s.err = ctx.Err()
+				}
+				return
+			}
+
+			if ev.Type == EventTimeout {
+				if idle <= 0 || time.Since(last) < idle {
+					if err := ctx.Err(); err != nil {
+						s.err = err
+						return
+					}
+					continue
+				}
+			}
+			last = time.Now()
+			ev = decode(ctx, ev)
+
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				s.err = ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return s
+}
@@ -0,0 +1,60 @@
+package gphoto2go
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEventStream(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := f.Events(ctx, 20*time.Millisecond)
+
+	f.QueueEvent(
+		&CameraEvent{Type: EventUnknown, Text: "Button 3"},
+		&CameraEvent{Type: EventFolderAdded, Folder: "/store_00010001/DCIM", File: "101CANON"},
+	)
+
+	exp := []CameraEventType{EventUnknown, EventFolderAdded, EventTimeout}
+	for _, typ := range exp {
+		select {
+		case ev := <-s.C:
+			if ev.Type != typ {
+				t.Fatalf("expected %s event, got %s", typ, ev.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s event, got nothing", typ)
+		}
+	}
+
+	cancel()
+	for range s.C {
+	}
+	if err := s.Err(); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestEventStreamError(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	failure := errors.New("usb unplugged")
+	f.Fail("WaitForEvent", failure)
+
+	s := f.Events(context.Background(), 0)
+	for range s.C {
+		t.Error("expected no events")
+	}
+	if err := s.Err(); err != failure {
+		t.Errorf("expected %v, got %v", failure, err)
+	}
+}
@@ -0,0 +1,609 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+const fakeCaptureFolder = "/store_00010001/DCIM/100FAKE"
+
+type fakeFile struct {
+	data       []byte
+	mtime      time.Time
+	perms      FilePermissions
+	downloaded bool
+}
+
+// Fake is a Backend with a virtual filesystem,
+// a configuration widget tree and a scriptable event queue.
+// It behaves like a connected camera without needing any hardware,
+// but it still uses libgphoto2 for widgets, files and errors
+// so code using it has to be built with libgphoto2 like Camera.
+// The zero value is not usable, create one with NewFake.
+type Fake struct {
+	mu      sync.Mutex
+	dirs    map[string]struct{}
+	files   map[string]*fakeFile
+	config  *CameraWidget
+	events  []*CameraEvent
+	notify  chan struct{}
+	errs    map[string]error
+	preview []byte
+
+	captureData []byte
+	captureExts []string
+	captures    int
+
+	// written are the paths of the widgets written by SetConfig
+	written []string
+
+	readLimit   int
+	blockCache  *ownedCache
+	readSupport readSupport
+	storages    []Storage
+}
+
+// NewFake creates a fake camera with an empty filesystem and
+// an empty configuration window.
+func NewFake() (*Fake, error) {
+	config, err := NewWidget(WidgetWindow, "", "Camera and Driver Configuration")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fake{
+		dirs:        map[string]struct{}{"/": {}},
+		files:       make(map[string]*fakeFile),
+		config:      config,
+		notify:      make(chan struct{}, 1),
+		errs:        make(map[string]error),
+		captureExts: []string{".JPG"},
+	}, nil
+}
+
+// AddFolder creates folder and all its parents.
+func (f *Fake) AddFolder(folder string) {
+	f.mu.Lock()
+	f.addFolder(folder)
+	f.mu.Unlock()
+}
+
+func (f *Fake) addFolder(folder string) {
+	for dir := path.Clean("/" + folder); ; dir = path.Dir(dir) {
+		f.dirs[dir] = struct{}{}
+		if dir == "/" {
+			return
+		}
+	}
+}
+
+// AddFile stores a file with the given contents, creating folder if needed.
+func (f *Fake) AddFile(folder, file string, data []byte) {
+	f.mu.Lock()
+	f.addFile(folder, file, data)
+	f.mu.Unlock()
+}
+
+func (f *Fake) addFile(folder, file string, data []byte) {
+	f.addFolder(folder)
+	f.files[path.Join("/", folder, file)] = &fakeFile{data: data, mtime: time.Now(), perms: PermRead | PermDelete}
+}
+
+// SetPreview sets the data returned by CapturePreview.
+func (f *Fake) SetPreview(data []byte) {
+	f.mu.Lock()
+	f.preview = data
+	f.mu.Unlock()
+}
+
+// SetCapture sets the data of the files created by a capture.
+// Each capture creates one file per extension, all sharing the same
+// base name like a camera shooting RAW+JPEG does. The default is a single ".JPG".
+func (f *Fake) SetCapture(data []byte, exts ...string) {
+	f.mu.Lock()
+	f.captureData = data
+	if len(exts) != 0 {
+		f.captureExts = exts
+	}
+	f.mu.Unlock()
+}
+
+// Fail makes every following call of the named method (e.g. "ListFiles")
+// return err. A nil err clears the failure.
+func (f *Fake) Fail(method string, err error) {
+	f.mu.Lock()
+	if err == nil {
+		delete(f.errs, method)
+	} else {
+		f.errs[method] = err
+	}
+	f.mu.Unlock()
+}
+
+// QueueEvent appends events to the queue consumed by WaitForEvent.
+func (f *Fake) QueueEvent(events ...*CameraEvent) {
+	f.mu.Lock()
+	f.events = append(f.events, events...)
+	f.mu.Unlock()
+
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (f *Fake) fail(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.errs[method]
+}
+
+// ListFolders lists the names of the folders in folder.
+func (f *Fake) ListFolders(folder string) ([]string, error) {
+	if err := f.fail("ListFolders"); err != nil {
+		return []string{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	folder = path.Clean("/" + folder)
+	if _, ok := f.dirs[folder]; !ok {
+		return []string{}, newError(ErrDirectoryNotFound)
+	}
+
+	names := make([]string, 0)
+	for dir := range f.dirs {
+		if dir != "/" && path.Dir(dir) == folder {
+			names = append(names, path.Base(dir))
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// RListFolders recursively lists all folders in folder.
+func (f *Fake) RListFolders(folder string) ([]string, error) {
+	return rListFolders(f, folder)
+}
+
+// ListFiles lists the names of the files in folder.
+func (f *Fake) ListFiles(folder string) ([]string, error) {
+	if err := f.fail("ListFiles"); err != nil {
+		return []string{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	folder = path.Clean("/" + folder)
+	if _, ok := f.dirs[folder]; !ok {
+		return []string{}, newError(ErrDirectoryNotFound)
+	}
+
+	names := make([]string, 0)
+	for file := range f.files {
+		if path.Dir(file) == folder {
+			names = append(names, path.Base(file))
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (f *Fake) file(folder, file string) (*fakeFile, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ff, ok := f.files[path.Join("/", folder, file)]
+	if !ok {
+		return nil, newError(ErrFileNotFound)
+	}
+	return ff, nil
+}
+
+// Info returns the size, modification time, permissions and MIME type of a file.
+func (f *Fake) Info(folder, file string) (*Info, error) {
+	if err := f.fail("Info"); err != nil {
+		return nil, err
+	}
+
+	ff, err := f.file(folder, file)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	info := &Info{
+		Fields:      InfoSize | InfoMTime | InfoPermissions | InfoStatus,
+		Size:        int64(len(ff.data)),
+		MTime:       ff.mtime.Unix(),
+		Permissions: ff.perms,
+		Downloaded:  ff.downloaded,
+	}
+	f.mu.Unlock()
+	if info.Type = mime.TypeByExtension(path.Ext(file)); info.Type != "" {
+		info.Fields |= InfoType
+	}
+
+	return info, nil
+}
+
+// SetInfo changes the permissions, downloaded status and modification time of a file.
+// Other fields are not supported.
+func (f *Fake) SetInfo(folder, file string, info *Info) error {
+	if err := f.fail("SetInfo"); err != nil {
+		return err
+	}
+	if !(InfoPermissions | InfoStatus | InfoMTime).Has(info.Fields) {
+		return newError(ErrNotSupported)
+	}
+
+	ff, err := f.file(folder, file)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if info.Fields.Has(InfoPermissions) {
+		ff.perms = info.Permissions
+	}
+	if info.Fields.Has(InfoStatus) {
+		ff.downloaded = info.Downloaded
+	}
+	if info.Fields.Has(InfoMTime) {
+		ff.mtime = time.Unix(info.MTime, 0)
+	}
+
+	return nil
+}
+
+// Protect is Camera.Protect.
+func (f *Fake) Protect(folder, file string, protect bool) error {
+	return f.SetInfo(folder, file, protectInfo(protect))
+}
+
+// MarkDownloaded is Camera.MarkDownloaded.
+func (f *Fake) MarkDownloaded(folder, file string, downloaded bool) error {
+	return f.SetInfo(folder, file, &Info{Fields: InfoStatus, Downloaded: downloaded})
+}
+
+// Rename is Camera.Rename, it always returns an *Error with code ErrNotSupported.
+func (f *Fake) Rename(folder, file, name string) error {
+	if err := f.fail("Rename"); err != nil {
+		return err
+	}
+
+	return newError(ErrNotSupported)
+}
+
+// FileReader returns a reader of the contents of a file.
+// Errors are returned by its Read method.
+func (f *Fake) FileReader(folder, file string) io.ReadCloser {
+	if err := f.fail("FileReader"); err != nil {
+		return io.NopCloser(errReader{err})
+	}
+
+	ff, err := f.file(folder, file)
+	if err != nil {
+		return io.NopCloser(errReader{err})
+	}
+
+	return io.NopCloser(bytes.NewReader(ff.data))
+}
+
+// SetReadLimit limits the bytes returned by a single read of a ReadSeeker,
+// like drivers transferring files in chunks. Zero removes the limit.
+func (f *Fake) SetReadLimit(n int) {
+	f.mu.Lock()
+	f.readLimit = n
+	f.mu.Unlock()
+}
+
+// ReadSeeker returns a ReadSeeker of a file like Camera.ReadSeeker.
+func (f *Fake) ReadSeeker(folder, file string) *ReadSeeker {
+	var src fileSource = &fakeSource{f: f, folder: folder, name: file}
+
+	f.mu.Lock()
+	bc := f.blockCache
+	f.mu.Unlock()
+	if bc != nil {
+		src = bc.source(src, path.Join(folder, file), FileNormal)
+	}
+
+	return newReadSeeker(context.Background(), src)
+}
+
+type fakeSource struct {
+	f      *Fake
+	folder string
+	name   string
+}
+
+func (s *fakeSource) readAt(_ context.Context, p []byte, off int64) (int, error) {
+	if err := s.f.fail("ReadSeeker"); err != nil {
+		return 0, err
+	}
+
+	ff, err := s.f.file(s.folder, s.name)
+	if err != nil {
+		return 0, err
+	}
+
+	s.f.mu.Lock()
+	limit := s.f.readLimit
+	s.f.mu.Unlock()
+	if limit > 0 && len(p) > limit {
+		p = p[:limit]
+	}
+	if off >= int64(len(ff.data)) {
+		return 0, nil
+	}
+
+	return copy(p, ff.data[off:]), nil
+}
+
+func (s *fakeSource) size(context.Context) (int64, error) {
+	info, err := s.f.Info(s.folder, s.name)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size, nil
+}
+
+func (s *fakeSource) close() {}
+
+// DeleteFile removes a file.
+func (f *Fake) DeleteFile(folder, file string) error {
+	if err := f.fail("DeleteFile"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p := path.Join("/", folder, file)
+	if _, ok := f.files[p]; !ok {
+		return newError(ErrFileNotFound)
+	}
+	delete(f.files, p)
+	if f.blockCache != nil {
+		f.blockCache.invalidate(folder, file)
+	}
+
+	return nil
+}
+
+// PutFile stores the contents of r in an existing folder.
+func (f *Fake) PutFile(folder, name string, r io.Reader) error {
+	if err := f.fail("PutFile"); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.dirs[path.Clean("/"+folder)]; !ok {
+		return newError(ErrDirectoryNotFound)
+	}
+	if _, ok := f.files[path.Join("/", folder, name)]; ok {
+		return newError(ErrFileExists)
+	}
+	f.addFile(folder, name, data)
+
+	return nil
+}
+
+// MakeDir creates the folder name in an existing folder.
+func (f *Fake) MakeDir(folder, name string) error {
+	if err := f.fail("MakeDir"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.dirs[path.Clean("/"+folder)]; !ok {
+		return newError(ErrDirectoryNotFound)
+	}
+	dir := path.Join("/", folder, name)
+	if _, ok := f.dirs[dir]; ok {
+		return newError(ErrDirectoryExists)
+	}
+	f.dirs[dir] = struct{}{}
+
+	return nil
+}
+
+// RemoveDir removes the folder name from folder,
+// it fails with ErrBadParameters if the folder is not empty.
+func (f *Fake) RemoveDir(folder, name string) error {
+	if err := f.fail("RemoveDir"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dir := path.Join("/", folder, name)
+	if _, ok := f.dirs[dir]; !ok || dir == "/" {
+		return newError(ErrDirectoryNotFound)
+	}
+	for d := range f.dirs {
+		if path.Dir(d) == dir && d != "/" {
+			return newError(ErrBadParameters)
+		}
+	}
+	for p := range f.files {
+		if path.Dir(p) == dir {
+			return newError(ErrBadParameters)
+		}
+	}
+	delete(f.dirs, dir)
+
+	return nil
+}
+
+// DeleteAll deletes all files in folder.
+func (f *Fake) DeleteAll(folder string) error {
+	if err := f.fail("DeleteAll"); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	folder = path.Clean("/" + folder)
+	if _, ok := f.dirs[folder]; !ok {
+		return newError(ErrDirectoryNotFound)
+	}
+	for p := range f.files {
+		if path.Dir(p) == folder {
+			delete(f.files, p)
+		}
+	}
+	if f.blockCache != nil {
+		f.blockCache.invalidateFolder(folder)
+	}
+
+	return nil
+}
+
+func (f *Fake) capture() []CameraFilePath {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.captures++
+	paths := make([]CameraFilePath, len(f.captureExts))
+	for i, ext := range f.captureExts {
+		paths[i] = CameraFilePath{
+			Folder: fakeCaptureFolder,
+			Name:   fmt.Sprintf("IMG_%04d%s", f.captures, ext),
+		}
+		f.addFile(paths[i].Folder, paths[i].Name, f.captureData)
+	}
+
+	return paths
+}
+
+// TriggerCapture creates the files of a capture and queues an
+// EventFileAdded for each of them, followed by an EventCaptureComplete.
+func (f *Fake) TriggerCapture() error {
+	if err := f.fail("TriggerCapture"); err != nil {
+		return err
+	}
+
+	paths := f.capture()
+	events := make([]*CameraEvent, 0, len(paths)+1)
+	for _, p := range paths {
+		events = append(events, &CameraEvent{Type: EventFileAdded, Folder: p.Folder, File: p.Name})
+	}
+	events = append(events, &CameraEvent{Type: EventCaptureComplete})
+	f.QueueEvent(events...)
+
+	return nil
+}
+
+// TriggerCaptureToFile creates the files of a capture and returns the first one.
+// The others are reported as EventFileAdded, like a camera does for RAW+JPEG,
+// followed by an EventCaptureComplete.
+func (f *Fake) TriggerCaptureToFile() (CameraFilePath, error) {
+	if err := f.fail("TriggerCaptureToFile"); err != nil {
+		return CameraFilePath{}, err
+	}
+
+	paths := f.capture()
+	events := make([]*CameraEvent, 0, len(paths))
+	for _, p := range paths[1:] {
+		events = append(events, &CameraEvent{Type: EventFileAdded, Folder: p.Folder, File: p.Name})
+	}
+	events = append(events, &CameraEvent{Type: EventCaptureComplete})
+	f.QueueEvent(events...)
+
+	return paths[0], nil
+}
+
+// CapturePreview returns the data set with SetPreview.
+func (f *Fake) CapturePreview() (CameraFile, error) {
+	if err := f.fail("CapturePreview"); err != nil {
+		return CameraFile{}, err
+	}
+
+	f.mu.Lock()
+	data := f.preview
+	f.mu.Unlock()
+
+	return newCameraFile(data)
+}
+
+// Update is a no-op as the configuration only lives in memory.
+func (f *Fake) Update() error {
+	return f.fail("Update")
+}
+
+// Config returns the root of the configuration tree.
+// Build it with NewWidget and CameraWidget.Append.
+func (f *Fake) Config() (*CameraWidget, error) {
+	if err := f.fail("Config"); err != nil {
+		return nil, err
+	}
+
+	return f.config, nil
+}
+
+// SetConfig clears the changed flags of the configuration tree like a camera writing it,
+// the values set on it are already stored.
+func (f *Fake) SetConfig() error {
+	if err := f.fail("SetConfig"); err != nil {
+		return err
+	}
+
+	return f.config.Walk(func(path string, w *CameraWidget) error {
+		if changed, err := w.Changed(); err != nil || !changed {
+			return err
+		}
+
+		f.mu.Lock()
+		f.written = append(f.written, path)
+		f.mu.Unlock()
+		return w.SetChanged(false)
+	})
+}
+
+// WaitForEvent pops the next queued event, waiting up to timeout milliseconds
+// for one to be queued. A timeout results in an event of type EventTimeout.
+func (f *Fake) WaitForEvent(timeout int) (*CameraEvent, error) {
+	if err := f.fail("WaitForEvent"); err != nil {
+		return nil, err
+	}
+
+	deadline := time.NewTimer(time.Duration(timeout) * time.Millisecond)
+	defer deadline.Stop()
+	for {
+		f.mu.Lock()
+		if len(f.events) != 0 {
+			ev := f.events[0]
+			f.events = f.events[1:]
+			f.mu.Unlock()
+			return ev, nil
+		}
+		f.mu.Unlock()
+
+		select {
+		case <-f.notify:
+		case <-deadline.C:
+			return &CameraEvent{Type: EventTimeout}, nil
+		}
+	}
+}
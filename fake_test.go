@@ -0,0 +1,166 @@
+package gphoto2go
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFakeFilesystem(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.AddFile("/store_00010001/DCIM/100CANON", "IMG_0001.JPG", []byte("jpeg"))
+	f.AddFile("/store_00010001/DCIM/100CANON", "IMG_0001.CR2", []byte("raw"))
+	f.AddFolder("/store_00010001/MISC")
+
+	folders, err := f.RListFolders("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expFolders := []string{
+		"/store_00010001",
+		"/store_00010001/DCIM",
+		"/store_00010001/DCIM/100CANON",
+		"/store_00010001/MISC",
+	}
+	if !reflect.DeepEqual(folders, expFolders) {
+		t.Errorf("expected folders %v, got %v", expFolders, folders)
+	}
+
+	files, err := f.ListFiles("/store_00010001/DCIM/100CANON")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"IMG_0001.CR2", "IMG_0001.JPG"}; !reflect.DeepEqual(files, exp) {
+		t.Errorf("expected files %v, got %v", exp, files)
+	}
+
+	info, err := f.Info("/store_00010001/DCIM/100CANON", "IMG_0001.CR2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size != 3 {
+		t.Errorf("expected size 3, got %d", info.Size)
+	}
+
+	r := f.FileReader("/store_00010001/DCIM/100CANON", "IMG_0001.JPG")
+	data, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "jpeg" {
+		t.Errorf("expected jpeg, got %q", data)
+	}
+
+	if err := f.DeleteFile("/store_00010001/DCIM/100CANON", "IMG_0001.JPG"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Info("/store_00010001/DCIM/100CANON", "IMG_0001.JPG")
+	if e, ok := err.(*Error); !ok || !e.Is(ErrFileNotFound) {
+		t.Errorf("expected ErrFileNotFound, got %v", err)
+	}
+
+	if _, err := f.ListFiles("/nope"); err == nil {
+		t.Error("expected error listing a missing folder")
+	}
+}
+
+func TestFakeCaptureEvents(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCapture([]byte("data"), ".CR2", ".JPG")
+
+	path, err := f.TriggerCaptureToFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.Name != "IMG_0001.CR2" {
+		t.Errorf("expected IMG_0001.CR2, got %s", path.Name)
+	}
+
+	ev, err := f.WaitForEvent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != EventFileAdded || ev.File != "IMG_0001.JPG" {
+		t.Errorf("expected IMG_0001.JPG to be added, got %+v", ev)
+	}
+
+	ev, err = f.WaitForEvent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != EventCaptureComplete {
+		t.Errorf("expected capture complete, got %+v", ev)
+	}
+
+	ev, err = f.WaitForEvent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Type != EventTimeout {
+		t.Errorf("expected timeout, got %+v", ev)
+	}
+
+	go f.QueueEvent(&CameraEvent{Type: EventUnknown})
+	if ev, _ = f.WaitForEvent(1000); ev.Type != EventUnknown {
+		t.Errorf("expected queued event, got %+v", ev)
+	}
+
+	failure := errors.New("usb unplugged")
+	f.Fail("WaitForEvent", failure)
+	if _, err = f.WaitForEvent(10); err != failure {
+		t.Errorf("expected scripted failure, got %v", err)
+	}
+}
+
+func TestFakeConfig(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, _ := f.Config()
+	section, err := NewWidget(WidgetSection, "settings", "Settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, err := NewWidget(WidgetText, "ownername", "Owner Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := section.Append(owner); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.Append(section); err != nil {
+		t.Fatal(err)
+	}
+	if err := owner.SetValue("someone"); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := root.Child("ownername")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := w.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "someone" {
+		t.Errorf("expected someone, got %v", v)
+	}
+
+	failure := newError(ErrIO)
+	f.Fail("Config", failure)
+	if root, err := f.Config(); root != nil || !errors.Is(err, failure) {
+		t.Errorf("expected no tree and the scripted failure, got %v, %v", root, err)
+	}
+}
@@ -0,0 +1,65 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+import "C"
+import "fmt"
+
+// FileType selects which data of a file is transferred.
+type FileType int
+
+// File types, most drivers only support some of them.
+const (
+	// FileNormal is the file itself.
+	FileNormal FileType = C.GP_FILE_TYPE_NORMAL
+	// FilePreview is a thumbnail, typically a small JPEG.
+	FilePreview FileType = C.GP_FILE_TYPE_PREVIEW
+	// FileRaw is the data as stored on the camera, without driver conversions.
+	FileRaw FileType = C.GP_FILE_TYPE_RAW
+	// FileAudio is the audio annotation of a file.
+	FileAudio FileType = C.GP_FILE_TYPE_AUDIO
+	// FileExif is the EXIF data of a file.
+	FileExif FileType = C.GP_FILE_TYPE_EXIF
+	// FileMetadata is driver specific metadata, e.g. the PTP object properties as XML.
+	FileMetadata FileType = C.GP_FILE_TYPE_METADATA
+)
+
+func (t FileType) String() string {
+	switch t {
+	case FileNormal:
+		return "normal"
+	case FilePreview:
+		return "preview"
+	case FileRaw:
+		return "raw"
+	case FileAudio:
+		return "audio"
+	case FileExif:
+		return "exif"
+	case FileMetadata:
+		return "metadata"
+	}
+	return fmt.Sprintf("FileType(%d)", int(t))
+}
+
+// FileOption configures a file transfer, see WithFileType and WithPriority.
+type FileOption func(*fileOptions)
+
+type fileOptions struct {
+	typ      FileType
+	priority Priority
+}
+
+func newFileOptions(opts []FileOption) fileOptions {
+	o := fileOptions{typ: FileNormal, priority: PriorityLow}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithFileType makes FileReaderContext, DownloadToContext, ReadSeekerContext and OpenContext
+// transfer data of type t instead of the file itself, e.g. FilePreview for thumbnails.
+func WithFileType(t FileType) FileOption {
+	return func(o *fileOptions) { o.typ = t }
+}
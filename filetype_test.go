@@ -0,0 +1,63 @@
+package gphoto2go
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestFileOptions(t *testing.T) {
+	if o := newFileOptions(nil); o.typ != FileNormal || o.priority != PriorityLow {
+		t.Errorf("expected FileNormal at PriorityLow by default, got %v %v", o.typ, o.priority)
+	}
+
+	o := newFileOptions([]FileOption{WithFileType(FilePreview), WithPriority(PriorityHigh + 1)})
+	if o.typ != FilePreview || o.priority != PriorityHigh {
+		t.Errorf("expected FilePreview at PriorityHigh, got %v %v", o.typ, o.priority)
+	}
+
+	if s := FileType(42).String(); s != "FileType(42)" {
+		t.Errorf("expected FileType(42), got %s", s)
+	}
+}
+
+// bytesSource is a fileSource of fixed data.
+type bytesSource []byte
+
+func (b bytesSource) readAt(_ context.Context, p []byte, off int64) (int, error) {
+	if off >= int64(len(b)) {
+		return 0, nil
+	}
+	return copy(p, b[off:]), nil
+}
+func (b bytesSource) size(context.Context) (int64, error) { return int64(len(b)), nil }
+func (b bytesSource) close()                              {}
+
+func TestBlockCacheFileTypes(t *testing.T) {
+	bc := NewBlockCache(BlockCacheOptions{})
+	o := bc.owner()
+	read := func(data string, typ FileType) string {
+		r := newReadSeeker(context.Background(), o.source(bytesSource(data), "/DCIM/IMG_0001.JPG", typ))
+		defer r.Close()
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(got)
+	}
+
+	if got := read("full", FileNormal); got != "full" {
+		t.Errorf("expected full, got %q", got)
+	}
+	if got := read("thumb", FilePreview); got != "thumb" {
+		t.Errorf("expected the preview not to be served from the blocks of the file, got %q", got)
+	}
+	if got := read("other", FilePreview); got != "thumb" {
+		t.Errorf("expected the cached preview, got %q", got)
+	}
+
+	bc.Invalidate("/DCIM", "IMG_0001.JPG")
+	if got := read("other", FilePreview); got != "other" {
+		t.Errorf("expected the preview to be invalidated along with the file, got %q", got)
+	}
+}
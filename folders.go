@@ -0,0 +1,124 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"unsafe"
+)
+
+// PutFile uploads the contents of r to folder as name.
+// The contents are streamed to the driver, which needs their size up front:
+// r is only read into memory if it has no Len method and is not an io.Seeker.
+func (c *Camera) PutFile(folder, name string, r io.Reader) error {
+	return c.PutFileContext(context.Background(), folder, name, r)
+}
+
+// PutFileContext is PutFile with a context.
+func (c *Camera) PutFileContext(ctx context.Context, folder, name string, r io.Reader) error {
+	size, err := readerSize(r)
+	if err != nil {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	h := &fileHandler{r: io.LimitReader(r, size), size: size}
+	cFile, handle, err := newHandlerFile(h)
+	if err != nil {
+		return err
+	}
+	defer handle.Delete()
+	defer C.gp_file_free(cFile)
+
+	cFolder := C.CString(folder)
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cFolder))
+	defer C.free(unsafe.Pointer(cName))
+	if bc := c.cache(); bc != nil {
+		defer bc.invalidate(folder, name)
+	}
+
+	err = c.callFile(ctx, PriorityLow, path.Join(folder, name), func() C.int {
+		return C.gp_camera_folder_put_file(c.camera, cFolder, cName, C.GP_FILE_TYPE_NORMAL, cFile, c.context)
+	})
+	if h.err != nil {
+		return h.err
+	}
+
+	return err
+}
+
+// readerSize returns the number of bytes left in r without reading them.
+func readerSize(r io.Reader) (int64, error) {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len()), nil
+	case io.Seeker:
+		cur, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := r.Seek(cur, io.SeekStart); err != nil {
+			return 0, err
+		}
+		return end - cur, nil
+	}
+
+	return 0, errors.New("unknown size")
+}
+
+// MakeDir creates the folder name in folder.
+func (c *Camera) MakeDir(folder, name string) error {
+	return c.MakeDirContext(context.Background(), folder, name)
+}
+
+// MakeDirContext is MakeDir with a context.
+func (c *Camera) MakeDirContext(ctx context.Context, folder, name string) error {
+	cFolder := C.CString(folder)
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cFolder))
+	defer C.free(unsafe.Pointer(cName))
+	return c.call(ctx, func() C.int { return C.gp_camera_folder_make_dir(c.camera, cFolder, cName, c.context) })
+}
+
+// RemoveDir removes the empty folder name from folder.
+func (c *Camera) RemoveDir(folder, name string) error {
+	return c.RemoveDirContext(context.Background(), folder, name)
+}
+
+// RemoveDirContext is RemoveDir with a context.
+func (c *Camera) RemoveDirContext(ctx context.Context, folder, name string) error {
+	cFolder := C.CString(folder)
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cFolder))
+	defer C.free(unsafe.Pointer(cName))
+	return c.call(ctx, func() C.int { return C.gp_camera_folder_remove_dir(c.camera, cFolder, cName, c.context) })
+}
+
+// DeleteAll deletes all files in folder, subfolders are left alone.
+func (c *Camera) DeleteAll(folder string) error {
+	return c.DeleteAllContext(context.Background(), folder)
+}
+
+// DeleteAllContext is DeleteAll with a context.
+func (c *Camera) DeleteAllContext(ctx context.Context, folder string) error {
+	cFolder := C.CString(folder)
+	defer C.free(unsafe.Pointer(cFolder))
+	if bc := c.cache(); bc != nil {
+		defer bc.invalidateFolder(folder)
+	}
+	return c.call(ctx, func() C.int { return C.gp_camera_folder_delete_all(c.camera, cFolder, c.context) })
+}
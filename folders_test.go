@@ -0,0 +1,88 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFakeFolders(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFolder("/store_00010001")
+
+	if err := f.MakeDir("/store_00010001", "PICTSTYL"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.MakeDir("/store_00010001", "PICTSTYL"); !isCode(err, ErrDirectoryExists) {
+		t.Errorf("expected ErrDirectoryExists, got %v", err)
+	}
+
+	if err := f.PutFile("/store_00010001/PICTSTYL", "STYLE.PF2", strings.NewReader("style")); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.PutFile("/nope", "STYLE.PF2", strings.NewReader("style")); !isCode(err, ErrDirectoryNotFound) {
+		t.Errorf("expected ErrDirectoryNotFound, got %v", err)
+	}
+
+	files, err := f.ListFiles("/store_00010001/PICTSTYL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"STYLE.PF2"}; !reflect.DeepEqual(files, exp) {
+		t.Errorf("expected files %v, got %v", exp, files)
+	}
+
+	if err := f.RemoveDir("/store_00010001", "PICTSTYL"); err == nil {
+		t.Error("expected removing a folder with files to fail")
+	}
+	if err := f.DeleteAll("/store_00010001/PICTSTYL"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RemoveDir("/store_00010001", "PICTSTYL"); err != nil {
+		t.Fatal(err)
+	}
+
+	folders, err := f.ListFolders("/store_00010001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(folders) != 0 {
+		t.Errorf("expected no folders, got %v", folders)
+	}
+}
+
+func TestReaderSize(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.WriteString("0123456789")
+	f.Seek(4, io.SeekStart)
+
+	for _, c := range []struct {
+		r    io.Reader
+		size int64
+	}{
+		{strings.NewReader("abc"), 3},
+		{bytes.NewBufferString("abcd"), 4},
+		{f, 6},
+	} {
+		if size, err := readerSize(c.r); err != nil || size != c.size {
+			t.Errorf("%T: expected %d, got %d %v", c.r, c.size, size, err)
+		}
+	}
+	if b, _ := io.ReadAll(f); string(b) != "456789" {
+		t.Errorf("expected the offset to be kept, read %q", b)
+	}
+
+	if _, err := readerSize(io.MultiReader(strings.NewReader("abc"))); err == nil {
+		t.Error("expected an error for a reader of unknown size")
+	}
+}
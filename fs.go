@@ -0,0 +1,296 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// fsBackend is what FS needs from a camera.
+type fsBackend interface {
+	ListFolders(folder string) ([]string, error)
+	ListFiles(folder string) ([]string, error)
+	Info(folder, file string) (*Info, error)
+	Open(folder, file string) *ReadSeeker
+}
+
+type contextCamera struct {
+	ctx context.Context
+	c   *Camera
+}
+
+func (c contextCamera) ListFolders(folder string) ([]string, error) {
+	return c.c.ListFoldersContext(c.ctx, folder)
+}
+
+func (c contextCamera) ListFiles(folder string) ([]string, error) {
+	return c.c.ListFilesContext(c.ctx, folder)
+}
+
+func (c contextCamera) Info(folder, file string) (*Info, error) {
+	return c.c.InfoContext(c.ctx, folder, file)
+}
+
+func (c contextCamera) Open(folder, file string) *ReadSeeker {
+	return c.c.OpenContext(c.ctx, folder, file)
+}
+
+// FS is the filesystem of a camera as an fs.FS, rooted at "/",
+// e.g. "store_00010001/DCIM/100CANON/IMG_0001.JPG".
+// It implements fs.ReadDirFS, fs.StatFS and fs.ReadFileFS.
+// Files implement io.Seeker and io.ReaderAt, see Camera.Open.
+type FS struct {
+	b fsBackend
+}
+
+// FS returns the filesystem of the camera.
+func (c *Camera) FS() *FS {
+	return c.FSContext(context.Background())
+}
+
+// FSContext is FS with a context used for all its operations.
+func (c *Camera) FSContext(ctx context.Context) *FS {
+	return &FS{b: contextCamera{ctx: ctx, c: c}}
+}
+
+// FS returns the filesystem of the fake like Camera.FS.
+func (f *Fake) FS() *FS {
+	return &FS{b: f}
+}
+
+// split converts a valid fs path into a camera folder and file name.
+func (fsys *FS) split(op, name string) (folder, file string, err error) {
+	if !fs.ValidPath(name) {
+		return "", "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return "/", "", nil
+	}
+
+	folder, file = path.Split("/" + name)
+	return path.Clean(folder), file, nil
+}
+
+func (fsys *FS) pathError(op, name string, err error) error {
+	if isCode(err, ErrFileNotFound) || isCode(err, ErrDirectoryNotFound) {
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// Stat returns the fs.FileInfo of a file or folder, its Sys is the *Info of files.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	folder, file, err := fsys.split("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		return dirInfo("."), nil
+	}
+
+	folders, err := fsys.b.ListFolders(folder)
+	if err != nil {
+		return nil, fsys.pathError("stat", name, err)
+	}
+	for _, f := range folders {
+		if f == file {
+			return dirInfo(file), nil
+		}
+	}
+
+	info, err := fsys.b.Info(folder, file)
+	if err != nil {
+		return nil, fsys.pathError("stat", name, err)
+	}
+
+	return &fileInfo{name: file, info: info}, nil
+}
+
+// Open opens a file or folder.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	fi, err := fsys.Stat(name)
+	if err != nil {
+		if pe, ok := err.(*fs.PathError); ok {
+			pe.Op = "open"
+		}
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		return &dirFile{fsys: fsys, name: name, info: fi}, nil
+	}
+
+	folder, file, _ := fsys.split("open", name)
+	return &cameraFSFile{ReadSeeker: fsys.b.Open(folder, file), info: fi}, nil
+}
+
+// ReadDir lists a folder sorted by name.
+// The fs.FileInfo of files is looked up when calling their Info method.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	folder, file, err := fsys.split("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	folder = path.Join(folder, file)
+
+	folders, err := fsys.b.ListFolders(folder)
+	if err != nil {
+		return nil, fsys.pathError("readdir", name, err)
+	}
+	files, err := fsys.b.ListFiles(folder)
+	if err != nil {
+		return nil, fsys.pathError("readdir", name, err)
+	}
+
+	entries := make([]fs.DirEntry, 0, len(folders)+len(files))
+	for _, f := range folders {
+		entries = append(entries, fs.FileInfoToDirEntry(dirInfo(f)))
+	}
+	for _, f := range files {
+		entries = append(entries, &fileEntry{fsys: fsys, folder: folder, name: f})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries, nil
+}
+
+// ReadFile reads a whole file.
+func (fsys *FS) ReadFile(name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, _ := f.Stat()
+	if fi.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, fi.Size()))
+	if _, err := f.(*cameraFSFile).WriteTo(buf); err != nil {
+		return nil, fsys.pathError("read", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fileInfo is the fs.FileInfo of a file.
+type fileInfo struct {
+	name string
+	info *Info
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.info.Size }
+func (fi *fileInfo) ModTime() time.Time { return time.Unix(fi.info.MTime, 0) }
+func (fi *fileInfo) IsDir() bool        { return false }
+func (fi *fileInfo) Sys() interface{}   { return fi.info }
+
+// Mode is read-only unless the file can be deleted.
+func (fi *fileInfo) Mode() fs.FileMode {
+	if !fi.info.Fields.Has(InfoPermissions) {
+		return 0o444
+	}
+
+	var mode fs.FileMode
+	if fi.info.Permissions&PermRead != 0 {
+		mode |= 0o444
+	}
+	if fi.info.Permissions&PermDelete != 0 {
+		mode |= 0o200
+	}
+	return mode
+}
+
+// dirInfo is the fs.FileInfo of a folder.
+type dirInfo string
+
+func (fi dirInfo) Name() string       { return string(fi) }
+func (fi dirInfo) Size() int64        { return 0 }
+func (fi dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0o555 }
+func (fi dirInfo) ModTime() time.Time { return time.Time{} }
+func (fi dirInfo) IsDir() bool        { return true }
+func (fi dirInfo) Sys() interface{}   { return nil }
+
+// fileEntry is the fs.DirEntry of a file.
+type fileEntry struct {
+	fsys   *FS
+	folder string
+	name   string
+}
+
+func (e *fileEntry) Name() string      { return e.name }
+func (e *fileEntry) IsDir() bool       { return false }
+func (e *fileEntry) Type() fs.FileMode { return 0 }
+
+func (e *fileEntry) Info() (fs.FileInfo, error) {
+	info, err := e.fsys.b.Info(e.folder, e.name)
+	if err != nil {
+		return nil, e.fsys.pathError("stat", path.Join(e.folder, e.name)[1:], err)
+	}
+	return &fileInfo{name: e.name, info: info}, nil
+}
+
+// cameraFSFile is an open file of an FS.
+type cameraFSFile struct {
+	*ReadSeeker
+	info fs.FileInfo
+}
+
+func (f *cameraFSFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+// dirFile is an open folder of an FS.
+type dirFile struct {
+	fsys    *FS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	read    bool
+	closed  bool
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *dirFile) Close() error {
+	d.closed = true
+	return nil
+}
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: fs.ErrClosed}
+	}
+	if !d.read {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.read = entries, true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n:n]
+	d.entries = d.entries[n:]
+
+	return entries, nil
+}
@@ -0,0 +1,60 @@
+package gphoto2go
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFS(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFile("/store_00010001/DCIM/100CANON", "IMG_0001.JPG", []byte("jpeg"))
+	f.AddFile("/store_00010001/DCIM/100CANON", "IMG_0001.CR2", []byte("raw"))
+	f.AddFile("/store_00010001/DCIM/101CANON", "IMG_0002.JPG", []byte("jpeg2"))
+	f.AddFolder("/store_00010001/MISC")
+
+	fsys := f.FS()
+	if err := fstest.TestFS(fsys,
+		"store_00010001/DCIM/100CANON/IMG_0001.JPG",
+		"store_00010001/DCIM/100CANON/IMG_0001.CR2",
+		"store_00010001/DCIM/101CANON/IMG_0002.JPG",
+		"store_00010001/MISC",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := fs.Glob(fsys, "store_00010001/DCIM/*/*.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("expected 2 JPEGs, got %v", matches)
+	}
+
+	data, err := fs.ReadFile(fsys, "store_00010001/DCIM/101CANON/IMG_0002.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "jpeg2" {
+		t.Errorf("expected jpeg2, got %q", data)
+	}
+
+	fi, err := fs.Stat(fsys, "store_00010001/DCIM/100CANON/IMG_0001.CR2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != 3 || fi.IsDir() {
+		t.Errorf("expected a file of 3 bytes, got %d, dir %v", fi.Size(), fi.IsDir())
+	}
+	if _, ok := fi.Sys().(*Info); !ok {
+		t.Errorf("expected Sys to be an *Info, got %T", fi.Sys())
+	}
+
+	if _, err := fsys.Open("store_00010001/nope"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+}
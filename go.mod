@@ -1,3 +1,3 @@
 module github.com/frizinak/gphoto2go
 
-go 1.13
+go 1.21
@@ -5,6 +5,7 @@ package gphoto2go
 // #include <stdlib.h>
 import "C"
 import (
+	"fmt"
 	"unsafe"
 )
 
@@ -17,17 +18,48 @@ const (
 // CameraEventType code
 type CameraEventType int
 
+// Event types reported by WaitForEvent
 const (
-	eventUnknown   CameraEventType = C.GP_EVENT_UNKNOWN
-	eventTimeout   CameraEventType = C.GP_EVENT_TIMEOUT
-	eventFileAdded CameraEventType = C.GP_EVENT_FILE_ADDED
+	EventUnknown         CameraEventType = C.GP_EVENT_UNKNOWN
+	EventTimeout         CameraEventType = C.GP_EVENT_TIMEOUT
+	EventFileAdded       CameraEventType = C.GP_EVENT_FILE_ADDED
+	EventFolderAdded     CameraEventType = C.GP_EVENT_FOLDER_ADDED
+	EventCaptureComplete CameraEventType = C.GP_EVENT_CAPTURE_COMPLETE
+	EventFileChanged     CameraEventType = C.GP_EVENT_FILE_CHANGED
 )
 
+func (t CameraEventType) String() string {
+	switch t {
+	case EventUnknown:
+		return "unknown"
+	case EventTimeout:
+		return "timeout"
+	case EventFileAdded:
+		return "file added"
+	case EventFolderAdded:
+		return "folder added"
+	case EventCaptureComplete:
+		return "capture complete"
+	case EventFileChanged:
+		return "file changed"
+	case EventConfigChanged:
+		return "config changed"
+	}
+	return fmt.Sprintf("CameraEventType(%d)", int(t))
+}
+
 // CameraEvent struct
+//
+// Folder and File are set for EventFileAdded, EventFileChanged and
+// EventFolderAdded, in which case File is the name of the new folder.
+// Text holds the driver specific description of an EventUnknown.
+// Config is set for an EventConfigChanged.
 type CameraEvent struct {
 	Type   CameraEventType
 	Folder string
 	File   string
+	Text   string
+	Config *ConfigChanged
 }
 
 // CameraFilePath struct
@@ -40,10 +72,17 @@ func cCameraEventToGoCameraEvent(voidPtr unsafe.Pointer, eventType C.CameraEvent
 	ce := new(CameraEvent)
 	ce.Type = CameraEventType(eventType)
 
-	if ce.Type == eventFileAdded {
+	if voidPtr == nil {
+		return ce
+	}
+
+	switch ce.Type {
+	case EventFileAdded, EventFolderAdded, EventFileChanged:
 		cameraFilePath := (*C.CameraFilePath)(voidPtr)
 		ce.File = C.GoString((*C.char)(&cameraFilePath.name[0]))
 		ce.Folder = C.GoString((*C.char)(&cameraFilePath.folder[0]))
+	case EventUnknown:
+		ce.Text = C.GoString((*C.char)(voidPtr))
 	}
 
 	return ce
@@ -63,8 +102,7 @@ func cameraListToMap(cameraList *C.CameraList) (map[string]string, int) {
 
 		C.gp_list_get_name(cameraList, C.int(i), &cKey)
 		C.gp_list_get_value(cameraList, C.int(i), &cVal)
-		defer C.free(unsafe.Pointer(cKey))
-		defer C.free(unsafe.Pointer(cVal))
+		// cKey and cVal are owned by the list
 		key := C.GoString(cKey)
 		val := C.GoString(cVal)
 
@@ -0,0 +1,187 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"context"
+	"unsafe"
+)
+
+// InfoFields flags the fields of an Info, PreviewInfo or AudioInfo the driver reported,
+// the others are zero.
+type InfoFields int
+
+const (
+	InfoType        InfoFields = C.GP_FILE_INFO_TYPE
+	InfoSize        InfoFields = C.GP_FILE_INFO_SIZE
+	InfoWidth       InfoFields = C.GP_FILE_INFO_WIDTH
+	InfoHeight      InfoFields = C.GP_FILE_INFO_HEIGHT
+	InfoPermissions InfoFields = C.GP_FILE_INFO_PERMISSIONS
+	InfoStatus      InfoFields = C.GP_FILE_INFO_STATUS
+	InfoMTime       InfoFields = C.GP_FILE_INFO_MTIME
+)
+
+// Has reports whether all fields of f2 are set.
+func (f InfoFields) Has(f2 InfoFields) bool {
+	return f&f2 == f2
+}
+
+// FilePermissions of a file on the camera.
+type FilePermissions int
+
+const (
+	PermNone   FilePermissions = C.GP_FILE_PERM_NONE
+	PermRead   FilePermissions = C.GP_FILE_PERM_READ
+	PermDelete FilePermissions = C.GP_FILE_PERM_DELETE
+	PermAll    FilePermissions = C.GP_FILE_PERM_ALL
+)
+
+// Info is the metadata of a file.
+type Info struct {
+	Fields InfoFields
+	// Type is the MIME type, e.g. "image/jpeg".
+	Type          string
+	Size          int64
+	MTime         int64
+	Width, Height int
+	Permissions   FilePermissions
+	// Downloaded is the status flag drivers keep for files transferred before.
+	Downloaded bool
+
+	// Preview and Audio are nil if the driver reported nothing about them.
+	Preview *PreviewInfo
+	Audio   *AudioInfo
+}
+
+// PreviewInfo is the metadata of the preview (thumbnail) of a file.
+type PreviewInfo struct {
+	Fields        InfoFields
+	Type          string
+	Size          int64
+	Width, Height int
+	Downloaded    bool
+}
+
+// AudioInfo is the metadata of the audio annotation of a file.
+type AudioInfo struct {
+	Fields     InfoFields
+	Type       string
+	Size       int64
+	Downloaded bool
+}
+
+func newInfo(cInfo *C.CameraFileInfo) *Info {
+	f := &cInfo.file
+	info := &Info{
+		Fields:      InfoFields(f.fields),
+		Type:        C.GoString(&f._type[0]),
+		Size:        int64(f.size),
+		MTime:       int64(f.mtime),
+		Width:       int(f.width),
+		Height:      int(f.height),
+		Permissions: FilePermissions(f.permissions),
+		Downloaded:  f.status == C.GP_FILE_STATUS_DOWNLOADED,
+	}
+
+	if p := &cInfo.preview; p.fields != C.GP_FILE_INFO_NONE {
+		info.Preview = &PreviewInfo{
+			Fields:     InfoFields(p.fields),
+			Type:       C.GoString(&p._type[0]),
+			Size:       int64(p.size),
+			Width:      int(p.width),
+			Height:     int(p.height),
+			Downloaded: p.status == C.GP_FILE_STATUS_DOWNLOADED,
+		}
+	}
+
+	if a := &cInfo.audio; a.fields != C.GP_FILE_INFO_NONE {
+		info.Audio = &AudioInfo{
+			Fields:     InfoFields(a.fields),
+			Type:       C.GoString(&a._type[0]),
+			Size:       int64(a.size),
+			Downloaded: a.status == C.GP_FILE_STATUS_DOWNLOADED,
+		}
+	}
+
+	return info
+}
+
+// newCInfo converts the fields of info flagged in info.Fields,
+// the fields of Preview and Audio are left unset.
+func newCInfo(info *Info) C.CameraFileInfo {
+	var cInfo C.CameraFileInfo
+	f := &cInfo.file
+	f.fields = C.CameraFileInfoFields(info.Fields)
+	f.size = C.uint64_t(info.Size)
+	f.mtime = C.time_t(info.MTime)
+	f.width = C.uint32_t(info.Width)
+	f.height = C.uint32_t(info.Height)
+	f.permissions = C.CameraFilePermissions(info.Permissions)
+	f.status = C.GP_FILE_STATUS_NOT_DOWNLOADED
+	if info.Downloaded {
+		f.status = C.GP_FILE_STATUS_DOWNLOADED
+	}
+	for i := 0; i < len(info.Type) && i < len(f._type)-1; i++ {
+		f._type[i] = C.char(info.Type[i])
+	}
+
+	return cInfo
+}
+
+// SetInfo changes the metadata of a file, only the fields flagged in info.Fields are changed.
+// Most drivers only support changing the permissions and the downloaded status,
+// an *Error with code ErrNotSupported is returned otherwise.
+func (c *Camera) SetInfo(folder, file string, info *Info) error {
+	return c.SetInfoContext(context.Background(), folder, file, info)
+}
+
+// SetInfoContext is SetInfo with a context.
+func (c *Camera) SetInfoContext(ctx context.Context, folder, file string, info *Info) error {
+	cInfo := newCInfo(info)
+	cFolder := C.CString(folder)
+	cFile := C.CString(file)
+	defer C.free(unsafe.Pointer(cFolder))
+	defer C.free(unsafe.Pointer(cFile))
+
+	return c.call(ctx, func() C.int {
+		return C.gp_camera_file_set_info(c.camera, cFolder, cFile, cInfo, c.context)
+	})
+}
+
+// Protect removes or restores the permission to delete a file.
+func (c *Camera) Protect(folder, file string, protect bool) error {
+	return c.ProtectContext(context.Background(), folder, file, protect)
+}
+
+// ProtectContext is Protect with a context.
+func (c *Camera) ProtectContext(ctx context.Context, folder, file string, protect bool) error {
+	return c.SetInfoContext(ctx, folder, file, protectInfo(protect))
+}
+
+// MarkDownloaded sets the downloaded status of a file.
+func (c *Camera) MarkDownloaded(folder, file string, downloaded bool) error {
+	return c.MarkDownloadedContext(context.Background(), folder, file, downloaded)
+}
+
+// MarkDownloadedContext is MarkDownloaded with a context.
+func (c *Camera) MarkDownloadedContext(ctx context.Context, folder, file string, downloaded bool) error {
+	return c.SetInfoContext(ctx, folder, file, &Info{Fields: InfoStatus, Downloaded: downloaded})
+}
+
+// Rename renames a file. libgphoto2 2.5 can not rename files,
+// it always returns an *Error with code ErrNotSupported.
+// Copying the file with PutFile and deleting it loses its protection,
+// mtime and downloaded status, and drivers may store the copy under another name.
+func (c *Camera) Rename(folder, file, name string) error {
+	return newError(ErrNotSupported)
+}
+
+func protectInfo(protect bool) *Info {
+	info := &Info{Fields: InfoPermissions, Permissions: PermRead | PermDelete}
+	if protect {
+		info.Permissions = PermRead
+	}
+	return info
+}
@@ -0,0 +1,93 @@
+package gphoto2go
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func TestInfoFields(t *testing.T) {
+	f := InfoSize | InfoMTime
+	if !f.Has(InfoSize) || !f.Has(InfoSize|InfoMTime) {
+		t.Errorf("expected %b to have size and mtime", f)
+	}
+	if f.Has(InfoType) || f.Has(InfoSize|InfoType) {
+		t.Errorf("expected %b not to have a type", f)
+	}
+}
+
+func TestFakeInfo(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFile("/store_00010001/DCIM/100CANON", "IMG_0001.JPG", []byte("jpeg"))
+
+	info, err := f.Info("/store_00010001/DCIM/100CANON", "IMG_0001.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Fields.Has(InfoType|InfoPermissions|InfoStatus) || info.Type != "image/jpeg" {
+		t.Errorf("expected the MIME type, permissions and status, got %+v", info)
+	}
+	if info.Downloaded || info.Preview != nil || info.Audio != nil {
+		t.Errorf("expected a file that was not downloaded without preview or audio, got %+v", info)
+	}
+
+	fi, err := fs.Stat(f.FS(), "store_00010001/DCIM/100CANON/IMG_0001.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode() != 0o644 {
+		t.Errorf("expected mode 0644 for a deletable file, got %v", fi.Mode())
+	}
+}
+
+func TestFakeSetInfo(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFile("/store_00010001/DCIM/100CANON", "IMG_0001.JPG", []byte("jpeg"))
+
+	if err := f.Protect("/store_00010001/DCIM/100CANON", "IMG_0001.JPG", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.MarkDownloaded("/store_00010001/DCIM/100CANON", "IMG_0001.JPG", true); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := f.Info("/store_00010001/DCIM/100CANON", "IMG_0001.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Permissions != PermRead || !info.Downloaded {
+		t.Errorf("expected a protected, downloaded file, got %+v", info)
+	}
+
+	err = f.SetInfo("/store_00010001/DCIM/100CANON", "IMG_0001.JPG", &Info{Fields: InfoType, Type: "image/png"})
+	if !isCode(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported changing the type, got %v", err)
+	}
+}
+
+func TestRename(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFile("/DCIM", "IMG_0001.JPG", []byte("jpeg"))
+
+	for _, b := range []interface {
+		Rename(folder, file, name string) error
+	}{new(Camera), f} {
+		if err := b.Rename("/DCIM", "IMG_0001.JPG", "holiday.jpg"); !isCode(err, ErrNotSupported) {
+			t.Errorf("%T: expected ErrNotSupported, got %v", b, err)
+		}
+	}
+
+	files, _ := f.ListFiles("/DCIM")
+	if !reflect.DeepEqual(files, []string{"IMG_0001.JPG"}) {
+		t.Errorf("expected the file to be kept, got %v", files)
+	}
+}
@@ -0,0 +1,158 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+//
+// extern void gphoto2goLog(GPLogLevel level, char *domain, char *str, void *data);
+//
+// static int gphoto2go_log_add(GPLogLevel level) {
+// 	return gp_log_add_func(level, (GPLogFunc)gphoto2goLog, NULL);
+// }
+import "C"
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// LevelData is the slog level of libgphoto2's GP_LOG_DATA messages,
+// which are hex dumps of the data exchanged with the camera.
+const LevelData = slog.LevelDebug - 4
+
+var logState struct {
+	mu      sync.Mutex
+	logger  *slog.Logger
+	level   int
+	id      C.int
+	enabled bool
+
+	history []string
+	next    int
+	full    bool
+}
+
+// SetLogger routes libgphoto2's log output to l, with the libgphoto2 domain
+// (e.g. "ptp2/usb") as the "domain" attribute.
+// Messages below the minimum level enabled on l are not even formatted by libgphoto2.
+// It is also the default logger for the context messages of all cameras,
+// see Camera.SetLogger. A nil l disables logging.
+func SetLogger(l *slog.Logger) {
+	logState.mu.Lock()
+	logState.logger = l
+	updateLogFunc()
+	logState.mu.Unlock()
+}
+
+// SetLogHistory keeps the last n debug lines of libgphoto2 in memory
+// and attaches them to every *Error, see Error.Log.
+// An n of zero or less disables the history.
+func SetLogHistory(n int) {
+	if n < 0 {
+		n = 0
+	}
+
+	logState.mu.Lock()
+	logState.history = make([]string, n)
+	logState.next = 0
+	logState.full = false
+	updateLogFunc()
+	logState.mu.Unlock()
+}
+
+// updateLogFunc (re)registers the libgphoto2 log function at the most verbose level
+// needed by the logger and history. logState.mu must be held.
+func updateLogFunc() {
+	level := -1
+	if len(logState.history) != 0 {
+		level = C.GP_LOG_DEBUG
+	}
+	if l := logState.logger; l != nil {
+		for _, gl := range []C.GPLogLevel{C.GP_LOG_ERROR, C.GP_LOG_VERBOSE, C.GP_LOG_DEBUG, C.GP_LOG_DATA} {
+			if int(gl) > level && l.Enabled(context.Background(), slogLevel(gl)) {
+				level = int(gl)
+			}
+		}
+	}
+
+	if logState.enabled && logState.level == level {
+		return
+	}
+	if logState.enabled {
+		C.gp_log_remove_func(logState.id)
+		logState.enabled = false
+	}
+	if level < 0 {
+		return
+	}
+
+	if id := C.gphoto2go_log_add(C.GPLogLevel(level)); id >= C.GP_OK {
+		logState.id, logState.level, logState.enabled = id, level, true
+	}
+}
+
+func slogLevel(level C.GPLogLevel) slog.Level {
+	switch level {
+	case C.GP_LOG_ERROR:
+		return slog.LevelError
+	case C.GP_LOG_VERBOSE:
+		return slog.LevelInfo
+	case C.GP_LOG_DEBUG:
+		return slog.LevelDebug
+	default:
+		return LevelData
+	}
+}
+
+func logMessage(level C.GPLogLevel, domain, msg string) {
+	logState.mu.Lock()
+	l := logState.logger
+	if len(logState.history) != 0 && level <= C.GP_LOG_DEBUG {
+		logState.history[logState.next] = domain + ": " + msg
+		logState.next = (logState.next + 1) % len(logState.history)
+		logState.full = logState.full || logState.next == 0
+	}
+	logState.mu.Unlock()
+
+	if l != nil {
+		l.Log(context.Background(), slogLevel(level), msg, "domain", domain)
+	}
+}
+
+// logHistory returns a copy of the log history, oldest line first.
+func logHistory() []string {
+	logState.mu.Lock()
+	defer logState.mu.Unlock()
+
+	if !logState.full {
+		return append([]string(nil), logState.history[:logState.next]...)
+	}
+
+	lines := make([]string, 0, len(logState.history))
+	lines = append(lines, logState.history[logState.next:]...)
+	return append(lines, logState.history[:logState.next]...)
+}
+
+// SetLogger sets the logger receiving the error, status and
+// message notifications of the camera driver, overriding the package
+// wide logger set with SetLogger.
+func (c *Camera) SetLogger(l *slog.Logger) {
+	c.ctxMu.Lock()
+	c.logger = l
+	c.ctxMu.Unlock()
+}
+
+func (c *Camera) logContext(level slog.Level, kind, msg string) {
+	c.ctxMu.Lock()
+	l := c.logger
+	c.ctxMu.Unlock()
+
+	if l == nil {
+		logState.mu.Lock()
+		l = logState.logger
+		logState.mu.Unlock()
+	}
+
+	if l != nil {
+		l.Log(context.Background(), level, msg, "domain", "context", "kind", kind)
+	}
+}
@@ -0,0 +1,57 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogHistory(t *testing.T) {
+	SetLogHistory(2)
+	defer SetLogHistory(0)
+
+	logMessage(0, "ptp2/usb", "one")
+	logMessage(2, "ptp2/usb", "two")
+	logMessage(2, "ptp2/library", "three")
+
+	var err *Error
+	if !errors.As(newError(ErrIO), &err) {
+		t.Fatal("expected an *Error")
+	}
+	exp := []string{"ptp2/usb: two", "ptp2/library: three"}
+	if !reflect.DeepEqual(err.Log(), exp) {
+		t.Errorf("expected log %v, got %v", exp, err.Log())
+	}
+}
+
+func TestLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	SetLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	defer SetLogger(nil)
+
+	logMessage(1, "gphoto2-camera", "Initializing camera...")
+	c := new(Camera)
+	c.logContext(slog.LevelError, "error", "Could not claim the USB device")
+
+	out := buf.String()
+	if !strings.Contains(out, `level=INFO msg="Initializing camera..." domain=gphoto2-camera`) {
+		t.Errorf("expected libgphoto2 log line, got %q", out)
+	}
+	if !strings.Contains(out, `level=ERROR msg="Could not claim the USB device" domain=context kind=error`) {
+		t.Errorf("expected context error line, got %q", out)
+	}
+}
+
+func TestLogHistoryNegative(t *testing.T) {
+	SetLogHistory(-1)
+	defer SetLogHistory(0)
+
+	logMessage(2, "ptp2/usb", "one")
+	var err *Error
+	if errors.As(newError(ErrIO), &err) && len(err.Log()) != 0 {
+		t.Errorf("expected no log, got %v", err.Log())
+	}
+}
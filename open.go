@@ -0,0 +1,234 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync"
+)
+
+// openMemoryLimit is the largest file Open downloads into memory
+// when falling back to a full download, larger ones go to a temporary file.
+const openMemoryLimit = 16 * 1024 * 1024
+
+// readSupport remembers whether a driver implements partial reads.
+type readSupport struct {
+	mu        sync.Mutex
+	known     bool
+	supported bool
+}
+
+func (s *readSupport) get() (known, supported bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.known, s.supported
+}
+
+func (s *readSupport) set(supported bool) {
+	s.mu.Lock()
+	s.known, s.supported = true, supported
+	s.mu.Unlock()
+}
+
+var driverReadSupport struct {
+	mu sync.Mutex
+	m  map[string]*readSupport
+}
+
+// readSupport returns what is known about partial reads of typ for the driver and model of c.
+func (c *Camera) readSupport(typ FileType) *readSupport {
+	key := typ.String()
+	if abilities, err := c.Abilities(); err == nil {
+		key += "\x00" + ToString(&abilities.library[0]) + "\x00" + ToString(&abilities.model[0])
+	}
+
+	driverReadSupport.mu.Lock()
+	defer driverReadSupport.mu.Unlock()
+	if driverReadSupport.m == nil {
+		driverReadSupport.m = make(map[string]*readSupport)
+	}
+	s, ok := driverReadSupport.m[key]
+	if !ok {
+		s = new(readSupport)
+		driverReadSupport.m[key] = s
+	}
+
+	return s
+}
+
+// Open returns a random-access reader of a file for any driver.
+// Drivers implementing gp_camera_file_read are read in parts like ReadSeeker,
+// others download the whole file on first read, into a temporary file if it is large.
+// Whether a driver supports partial reads is found out on first use and remembered.
+// Errors, like a missing file, are returned by the reads.
+func (c *Camera) Open(folder, file string) *ReadSeeker {
+	return c.OpenContext(context.Background(), folder, file)
+}
+
+// OpenContext is Open with a context used for all its reads and options,
+// see WithFileType to read thumbnails and such.
+func (c *Camera) OpenContext(ctx context.Context, folder, file string, opts ...FileOption) *ReadSeeker {
+	r := c.ReadSeekerContext(ctx, folder, file, opts...)
+	dl := func(ctx context.Context, w io.Writer) (int64, error) {
+		return c.DownloadToContext(ctx, folder, file, w, opts...)
+	}
+
+	return openFile(r, c.readSupport(newFileOptions(opts).typ), dl)
+}
+
+// Open is Camera.Open.
+// Partial reads can be made unsupported with Fail("ReadSeeker", newError(ErrNotSupported)).
+func (f *Fake) Open(folder, file string) *ReadSeeker {
+	r := f.ReadSeeker(folder, file)
+	dl := func(ctx context.Context, w io.Writer) (int64, error) {
+		return download(ctx, f, folder, file, w)
+	}
+
+	return openFile(r, &f.readSupport, dl)
+}
+
+// openFile makes r fall back to downloading with dl when partial reads are not supported.
+func openFile(r *ReadSeeker, support *readSupport, dl func(context.Context, io.Writer) (int64, error)) *ReadSeeker {
+	fallback := &downloadSource{download: dl, info: r.src}
+	if known, supported := support.get(); known {
+		if !supported {
+			r.src = fallback
+		}
+		return r
+	}
+
+	r.src = &probeSource{partial: r.src, fallback: fallback, support: support}
+	return r
+}
+
+// probeSource tries a partial read and switches to the fallback if the driver does not support it.
+type probeSource struct {
+	partial  fileSource
+	fallback fileSource
+	support  *readSupport
+
+	mu  sync.Mutex
+	use fileSource
+}
+
+func (s *probeSource) readAt(ctx context.Context, p []byte, off int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.use != nil {
+		return s.use.readAt(ctx, p, off)
+	}
+
+	n, err := s.partial.readAt(ctx, p, off)
+	if isCode(err, ErrNotSupported) {
+		s.support.set(false)
+		s.use = s.fallback
+		return s.use.readAt(ctx, p, off)
+	}
+	if err == nil {
+		s.support.set(true)
+		s.use = s.partial
+	}
+
+	return n, err
+}
+
+func (s *probeSource) size(ctx context.Context) (int64, error) {
+	return s.partial.size(ctx)
+}
+
+// close closes partial through the fallback.
+func (s *probeSource) close() {
+	s.fallback.close()
+}
+
+// downloadSource downloads the whole file on first read.
+type downloadSource struct {
+	download func(context.Context, io.Writer) (int64, error)
+	// info looks up the size before downloading, it is closed along.
+	info fileSource
+
+	mu   sync.Mutex
+	done bool
+	err  error
+	data []byte
+	tmp  *os.File
+	n    int64
+}
+
+func (s *downloadSource) load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return s.err
+	}
+
+	size, err := s.info.size(ctx)
+	if err != nil || size > openMemoryLimit {
+		s.err = s.spill(ctx)
+	} else {
+		buf := bytes.NewBuffer(make([]byte, 0, size))
+		s.n, s.err = s.download(ctx, buf)
+		s.data = buf.Bytes()
+	}
+
+	// A cancelled download can be retried with another context.
+	if s.err == nil || ctx.Err() == nil {
+		s.done = true
+	}
+
+	return s.err
+}
+
+func (s *downloadSource) spill(ctx context.Context) error {
+	tmp, err := os.CreateTemp("", "gphoto2go-*")
+	if err != nil {
+		return err
+	}
+	if s.n, err = s.download(ctx, tmp); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	s.tmp = tmp
+
+	return nil
+}
+
+func (s *downloadSource) readAt(ctx context.Context, p []byte, off int64) (int, error) {
+	if err := s.load(ctx); err != nil {
+		return 0, err
+	}
+	if off >= s.n {
+		return 0, nil
+	}
+
+	if s.tmp == nil {
+		return copy(p, s.data[off:]), nil
+	}
+
+	n, err := s.tmp.ReadAt(p, off)
+	if err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+func (s *downloadSource) size(ctx context.Context) (int64, error) {
+	if err := s.load(ctx); err != nil {
+		return 0, err
+	}
+	return s.n, nil
+}
+
+func (s *downloadSource) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tmp != nil {
+		s.tmp.Close()
+		os.Remove(s.tmp.Name())
+		s.tmp = nil
+	}
+	s.data = nil
+	s.info.close()
+}
@@ -0,0 +1,98 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestOpenPartialReads(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFile("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2", []byte("0123456789"))
+
+	r := f.Open("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+	p := make([]byte, 4)
+	if _, err := r.ReadAt(p, 6); err != nil || string(p) != "6789" {
+		t.Errorf("expected 6789, got %q, %v", p, err)
+	}
+
+	if known, supported := f.readSupport.get(); !known || !supported {
+		t.Errorf("expected partial reads to be known as supported, got %v, %v", known, supported)
+	}
+}
+
+func TestOpenFallback(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	f.AddFile("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2", data)
+	f.Fail("ReadSeeker", newError(ErrNotSupported))
+
+	for i := 0; i < 2; i++ {
+		r := f.Open("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+		if _, err := r.Seek(-5, io.SeekEnd); err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "56789" {
+			t.Errorf("expected 56789, got %q", got)
+		}
+	}
+
+	if known, supported := f.readSupport.get(); !known || supported {
+		t.Errorf("expected partial reads to be known as unsupported, got %v, %v", known, supported)
+	}
+}
+
+func TestOpenSpill(t *testing.T) {
+	var n int
+	dl := &downloadSource{
+		download: func(_ context.Context, w io.Writer) (int64, error) {
+			n++
+			m, err := w.Write([]byte("spilled"))
+			return int64(m), err
+		},
+		info: sizelessSource{},
+	}
+	r := newReadSeeker(context.Background(), dl)
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "spilled" {
+		t.Errorf("expected spilled, got %q", got)
+	}
+	if dl.tmp == nil {
+		t.Fatal("expected a file of unknown size to be spilled to a temporary file")
+	}
+	name := dl.tmp.Name()
+
+	r.Close()
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary file to be removed, got %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected a single download, got %d", n)
+	}
+}
+
+type sizelessSource struct{}
+
+func (sizelessSource) readAt(context.Context, []byte, int64) (int, error) {
+	return 0, newError(ErrNotSupported)
+}
+func (sizelessSource) size(context.Context) (int64, error) { return 0, errSizeUnknown }
+func (sizelessSource) close()                              {}
@@ -0,0 +1,186 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+import "C"
+import (
+	"sync"
+	"time"
+)
+
+// Progress of a long running operation.
+// The driver reports Done and Total in units of its own, e.g. PTP cameras count blocks of 200KB.
+// Downloads DownloadTo splits into parts are reported in bytes by gphoto2go instead,
+// with a single Finished report.
+type Progress struct {
+	// Path of the file being transferred, empty for other operations.
+	Path string
+	// Text describes the operation, e.g. "Downloading IMG_0001.CR2...".
+	Text  string
+	Done  int64
+	Total int64
+	// Finished is set on the last report of an operation.
+	Finished bool
+}
+
+// ProgressFunc receives progress reports.
+// It is called from within libgphoto2 and should return quickly.
+type ProgressFunc func(Progress)
+
+// SetProgressFunc sets the function receiving the progress of the operations of c.
+// A nil fn disables progress reporting.
+func (c *Camera) SetProgressFunc(fn ProgressFunc) {
+	c.ctxMu.Lock()
+	c.progressFunc = fn
+	c.ctxMu.Unlock()
+}
+
+// reportProgress reports progress tracked by gphoto2go itself,
+// e.g. of a transfer made of several operations.
+func (c *Camera) reportProgress(p Progress) {
+	c.ctxMu.Lock()
+	fn := c.progressFunc
+	c.ctxMu.Unlock()
+
+	if fn != nil {
+		fn(p)
+	}
+}
+
+func (c *Camera) progressStart(target float64, text string) C.uint {
+	c.ctxMu.Lock()
+	if c.quiet {
+		// Ids start at 1, updates of 0 are dropped.
+		c.ctxMu.Unlock()
+		return 0
+	}
+	if c.progress == nil {
+		c.progress = make(map[C.uint]*Progress)
+	}
+	c.progressID++
+	id := c.progressID
+	p := &Progress{Path: c.path, Text: text, Total: int64(target)}
+	c.progress[id] = p
+	fn, report := c.progressFunc, *p
+	c.ctxMu.Unlock()
+
+	if fn != nil {
+		fn(report)
+	}
+
+	return id
+}
+
+func (c *Camera) progressUpdate(id C.uint, current float64) {
+	c.ctxMu.Lock()
+	p, ok := c.progress[id]
+	if !ok {
+		c.ctxMu.Unlock()
+		return
+	}
+	p.Done = int64(current)
+	fn, report := c.progressFunc, *p
+	c.ctxMu.Unlock()
+
+	if fn != nil {
+		fn(report)
+	}
+}
+
+func (c *Camera) progressStop(id C.uint) {
+	c.ctxMu.Lock()
+	p, ok := c.progress[id]
+	if !ok {
+		c.ctxMu.Unlock()
+		return
+	}
+	delete(c.progress, id)
+	p.Finished = true
+	fn, report := c.progressFunc, *p
+	c.ctxMu.Unlock()
+
+	if fn != nil {
+		fn(report)
+	}
+}
+
+// ProgressMeter calculates the throughput and remaining time of a transfer
+// from successive progress updates.
+// Feed it the Progress of a single file, or accumulated totals to track a batch.
+// The zero value is ready to use.
+type ProgressMeter struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	start time.Time
+	base  int64
+	done  int64
+	total int64
+	at    time.Time
+}
+
+// Update records that done out of total units have been transferred.
+func (m *ProgressMeter) Update(done, total int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if m.now != nil {
+		now = m.now()
+	}
+
+	if m.start.IsZero() || done < m.done {
+		m.start, m.base = now, done
+	}
+	m.done, m.total, m.at = done, total, now
+}
+
+// UpdateProgress is Update with the values of p.
+func (m *ProgressMeter) UpdateProgress(p Progress) {
+	m.Update(p.Done, p.Total)
+}
+
+// Rate returns the average throughput in units per second since the first update.
+func (m *ProgressMeter) Rate() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rate()
+}
+
+func (m *ProgressMeter) rate() float64 {
+	elapsed := m.at.Sub(m.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(m.done-m.base) / elapsed
+}
+
+// ETA returns the estimated time until done reaches total
+// or -1 if it can't be estimated yet.
+func (m *ProgressMeter) ETA() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.total <= 0 {
+		return -1
+	}
+	if m.done >= m.total {
+		return 0
+	}
+	rate := m.rate()
+	if rate <= 0 {
+		return -1
+	}
+
+	return time.Duration(float64(m.total-m.done) / rate * float64(time.Second))
+}
+
+// Fraction returns the completed fraction between 0 and 1.
+func (m *ProgressMeter) Fraction() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.total <= 0 {
+		return 0
+	}
+	return float64(m.done) / float64(m.total)
+}
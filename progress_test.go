@@ -0,0 +1,69 @@
+package gphoto2go
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressMeter(t *testing.T) {
+	now := time.Unix(0, 0)
+	m := &ProgressMeter{now: func() time.Time { return now }}
+
+	if eta := m.ETA(); eta != -1 {
+		t.Errorf("expected unknown ETA before any update, got %s", eta)
+	}
+
+	m.Update(0, 1000)
+	now = now.Add(2 * time.Second)
+	m.UpdateProgress(Progress{Done: 400, Total: 1000})
+
+	if rate := m.Rate(); rate != 200 {
+		t.Errorf("expected 200 units/s, got %f", rate)
+	}
+	if eta := m.ETA(); eta != 3*time.Second {
+		t.Errorf("expected 3s remaining, got %s", eta)
+	}
+	if f := m.Fraction(); f != 0.4 {
+		t.Errorf("expected 0.4 done, got %f", f)
+	}
+
+	// A new transfer restarts the measurement.
+	now = now.Add(time.Minute)
+	m.Update(0, 500)
+	now = now.Add(time.Second)
+	m.Update(100, 500)
+	if rate := m.Rate(); rate != 100 {
+		t.Errorf("expected 100 units/s after restart, got %f", rate)
+	}
+
+	m.Update(500, 500)
+	if eta := m.ETA(); eta != 0 {
+		t.Errorf("expected no time remaining, got %s", eta)
+	}
+}
+
+func TestCameraProgress(t *testing.T) {
+	var reports []Progress
+	c := new(Camera)
+	c.SetProgressFunc(func(p Progress) { reports = append(reports, p) })
+	c.path = "/DCIM/IMG_0001.CR2"
+
+	id := c.progressStart(1000, "Downloading...")
+	c.progressUpdate(id, 500)
+	c.progressStop(id)
+	c.progressUpdate(id, 600)
+
+	exp := []Progress{
+		{Path: "/DCIM/IMG_0001.CR2", Text: "Downloading...", Total: 1000},
+		{Path: "/DCIM/IMG_0001.CR2", Text: "Downloading...", Done: 500, Total: 1000},
+		{Path: "/DCIM/IMG_0001.CR2", Text: "Downloading...", Done: 500, Total: 1000, Finished: true},
+	}
+	if len(reports) != len(exp) {
+		t.Fatalf("expected %d reports, got %d: %+v", len(exp), len(reports), reports)
+	}
+	for i := range exp {
+		if reports[i] != exp[i] {
+			t.Errorf("report %d: expected %+v, got %+v", i, exp[i], reports[i])
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package gphoto2go
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EventConfigChanged is the type of the events Camera.Events decodes from
+// the "PTP Property ... changed" EventUnknown events of PTP cameras,
+// e.g. after turning a dial. It has no libgphoto2 counterpart.
+const EventConfigChanged CameraEventType = -1
+
+// ConfigChanged describes a changed setting.
+type ConfigChanged struct {
+	// Property is the PTP device property code.
+	Property uint16
+	// Name of the matching CameraWidget, empty if unknown.
+	Name string
+	// Value is the new value, empty if the driver did not report it
+	// and the configuration is not refreshed, see Camera.SetConfigRefresh.
+	Value string
+}
+
+// Newer drivers append the widget and its new value.
+var propertyChangedRE = regexp.MustCompile(`^PTP Property ([0-9a-fA-F]{4}) changed(?:, "(.*)" to "(.*)")?$`)
+
+// ptpPropertyWidgets maps standard PTP device properties to widget names.
+var ptpPropertyWidgets = map[uint16]string{
+	0x5001: "batterylevel",
+	0x5003: "imagesize",
+	0x5005: "whitebalance",
+	0x5007: "f-number",
+	0x5008: "focallength",
+	0x500a: "focusmode",
+	0x500b: "exposuremetermode",
+	0x500c: "flashmode",
+	0x500d: "shutterspeed",
+	0x500e: "expprogram",
+	0x500f: "iso",
+	0x5010: "exposurecompensation",
+	0x5011: "datetime",
+}
+
+// eosPropertyWidgets maps Canon EOS vendor properties to widget names.
+var eosPropertyWidgets = map[uint16]string{
+	0xd101: "aperture",
+	0xd102: "shutterspeed",
+	0xd103: "iso",
+	0xd104: "exposurecompensation",
+	0xd105: "autoexposuremode",
+	0xd106: "drivemode",
+	0xd107: "meteringmode",
+	0xd108: "focusmode",
+	0xd109: "whitebalance",
+	0xd10a: "colortemperature",
+	0xd110: "picturestyle",
+	0xd111: "batterylevel",
+	0xd120: "imageformat",
+}
+
+// parsePropertyChanged parses the text of an EventUnknown into a ConfigChanged.
+// widget is the quoted name or label of newer drivers.
+func parsePropertyChanged(text string) (cc *ConfigChanged, widget string, ok bool) {
+	m := propertyChangedRE.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return nil, "", false
+	}
+
+	code, err := strconv.ParseUint(m[1], 16, 16)
+	if err != nil {
+		return nil, "", false
+	}
+
+	return &ConfigChanged{Property: uint16(code), Value: m[3]}, m[2], true
+}
+
+// decodePropertyEvent turns a "PTP Property ... changed" EventUnknown into
+// an EventConfigChanged, looking up widget names in config, which may be nil.
+// Other events are returned as is.
+func decodePropertyEvent(ev *CameraEvent, config *CameraWidget, vendor map[uint16]string) *CameraEvent {
+	if ev.Type != EventUnknown {
+		return ev
+	}
+
+	cc, widget, ok := parsePropertyChanged(ev.Text)
+	if !ok {
+		return ev
+	}
+
+	if widget != "" && config != nil {
+		if w := findWidget(config, widget); w != nil {
+			cc.Name, _ = w.Name()
+		}
+	}
+	if cc.Name == "" {
+		if name, ok := ptpPropertyWidgets[cc.Property]; ok {
+			cc.Name = name
+		} else if name, ok := vendor[cc.Property]; ok {
+			cc.Name = name
+		}
+	}
+
+	return &CameraEvent{Type: EventConfigChanged, Text: ev.Text, Config: cc}
+}
+
+// findWidget finds the widget whose name, or else label, is key ignoring case,
+// as drivers report either in any case, e.g. "ISO" for the widget "iso".
+func findWidget(config *CameraWidget, key string) *CameraWidget {
+	var byName, byLabel *CameraWidget
+	config.Walk(func(_ string, w *CameraWidget) error {
+		if name, err := w.Name(); err == nil && byName == nil && strings.EqualFold(name, key) {
+			byName = w
+		}
+		if label, err := w.Label(); err == nil && byLabel == nil && strings.EqualFold(label, key) {
+			byLabel = w
+		}
+		return nil
+	})
+
+	if byName != nil {
+		return byName
+	}
+	return byLabel
+}
+
+// refreshSetting reads the setting of cc from b, filling in its value if the driver did not report it.
+// The widget of the tree returned by Config is updated unless it has a change staged.
+func refreshSetting(ctx context.Context, b settingBackend, cc *ConfigChanged) {
+	if cc.Name == "" {
+		return
+	}
+
+	v, err := getSetting(ctx, b, cc.Name)
+	if err != nil {
+		return
+	}
+	if cc.Value == "" {
+		cc.Value = fmt.Sprint(v)
+	}
+
+	b.withConfig(ctx, func(root *CameraWidget) {
+		w, err := root.Child(cc.Name)
+		if err != nil {
+			return
+		}
+		if changed, err := w.Changed(); err == nil && !changed && w.setValue(v) == nil {
+			w.SetChanged(false)
+		}
+	})
+}
+
+// SetConfigRefresh makes Events read the setting of every EventConfigChanged from the camera,
+// so the configuration returned by Config reflects changes made on the camera itself.
+// Only that widget is updated, and not if it has a change staged, see CameraWidget.Changed.
+func (c *Camera) SetConfigRefresh(refresh bool) {
+	c.ctxMu.Lock()
+	c.configRefresh = refresh
+	c.ctxMu.Unlock()
+}
+
+// decodeEvent decodes the events of Camera.Events.
+func (c *Camera) decodeEvent(ctx context.Context, ev *CameraEvent) *CameraEvent {
+	if ev.Type != EventUnknown {
+		return ev
+	}
+
+	var vendor map[uint16]string
+	if model, err := c.Model(); err == nil && strings.HasPrefix(model, "Canon EOS") {
+		vendor = eosPropertyWidgets
+	}
+
+	// Look up the widget on the queue, so Update does not free the tree meanwhile.
+	var decoded *CameraEvent
+	c.withConfig(ctx, func(root *CameraWidget) {
+		decoded = decodePropertyEvent(ev, root, vendor)
+	})
+	if decoded == nil {
+		decoded = decodePropertyEvent(ev, nil, vendor)
+	}
+	if decoded.Type != EventConfigChanged {
+		return decoded
+	}
+
+	c.ctxMu.Lock()
+	refresh := c.configRefresh
+	c.ctxMu.Unlock()
+
+	if refresh {
+		refreshSetting(ctx, c, decoded.Config)
+	}
+
+	return decoded
+}
@@ -0,0 +1,129 @@
+package gphoto2go
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPropertyChangedEvents(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := f.Config()
+	iso, err := NewWidget(WidgetRadio, "iso", "ISO Speed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := root.Append(iso); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := f.Events(ctx, 0)
+
+	f.QueueEvent(
+		&CameraEvent{Type: EventUnknown, Text: `PTP Property d103 changed, "ISO Speed" to "400"`},
+		&CameraEvent{Type: EventUnknown, Text: `PTP Property d103 changed, "iso" to "800"`},
+		&CameraEvent{Type: EventUnknown, Text: "PTP Property 500d changed"},
+		&CameraEvent{Type: EventUnknown, Text: "PTP Property d1ff changed"},
+		&CameraEvent{Type: EventUnknown, Text: "Button 3"},
+	)
+
+	exp := []*ConfigChanged{
+		{Property: 0xd103, Name: "iso", Value: "400"},
+		{Property: 0xd103, Name: "iso", Value: "800"},
+		{Property: 0x500d, Name: "shutterspeed"},
+		{Property: 0xd1ff},
+		nil,
+	}
+	for _, cc := range exp {
+		var ev *CameraEvent
+		select {
+		case ev = <-s.C:
+		case <-time.After(time.Second):
+			t.Fatal("expected an event")
+		}
+
+		if cc == nil {
+			if ev.Type != EventUnknown || ev.Config != nil {
+				t.Errorf("expected an unknown event, got %+v", ev)
+			}
+			continue
+		}
+		if ev.Type != EventConfigChanged || *ev.Config != *cc {
+			t.Errorf("expected %+v, got %+v %+v", cc, ev, ev.Config)
+		}
+	}
+}
+
+func TestPropertyChangedCase(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := f.Config()
+	section, _ := NewWidget(WidgetSection, "imgsettings", "Image Settings")
+	iso, _ := NewWidget(WidgetRadio, "iso", "ISO Speed")
+	section.Append(iso)
+	root.Append(section)
+
+	for _, text := range []string{
+		`PTP Property d1ff changed, "ISO" to "400"`,
+		`PTP Property d1ff changed, "iso speed" to "400"`,
+	} {
+		ev := decodePropertyEvent(&CameraEvent{Type: EventUnknown, Text: text}, root, nil)
+		if ev.Config == nil || ev.Config.Name != "iso" {
+			t.Errorf("%s: expected iso, got %+v", text, ev.Config)
+		}
+	}
+}
+
+func TestRefreshSetting(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := f.Config()
+	for _, name := range []string{"iso", "shutterspeed"} {
+		w, _ := NewWidget(WidgetText, name, name)
+		root.Append(w)
+	}
+
+	staged, err := root.clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer staged.Free()
+	w, _ := staged.Child("shutterspeed")
+	w.SetValue("1/100")
+
+	// Turning the dials on the camera.
+	for name, value := range map[string]string{"iso": "800", "shutterspeed": "1/50"} {
+		w, _ := root.Child(name)
+		w.SetValue(value)
+	}
+
+	b := &stagedBackend{Fake: f, staged: staged, noSingle: true}
+	for name, exp := range map[string]string{"iso": "800", "shutterspeed": "1/100"} {
+		cc := &ConfigChanged{Name: name}
+		refreshSetting(context.Background(), b, cc)
+		if name == "iso" && cc.Value != "800" {
+			t.Errorf("expected the value to be filled in, got %q", cc.Value)
+		}
+
+		w, _ := staged.Child(name)
+		if v, _ := w.Value(); v != exp {
+			t.Errorf("%s: expected %s, got %v", name, exp, v)
+		}
+	}
+	w, _ = staged.Child("iso")
+	if changed, _ := w.Changed(); changed {
+		t.Error("expected the refreshed iso to be unchanged")
+	}
+	if len(f.written) != 0 {
+		t.Errorf("expected nothing to be written, got %v", f.written)
+	}
+}
@@ -0,0 +1,144 @@
+package gphoto2go
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Priority of an operation in the queue of a Camera.
+type Priority int
+
+// Operation priorities.
+// File transfers default to PriorityLow, previews to PriorityHigh
+// and everything else to PriorityNormal.
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// WithPriority makes a file transfer run at priority p instead of PriorityLow,
+// e.g. PriorityHigh to read a thumbnail ahead of queued downloads.
+func WithPriority(p Priority) FileOption {
+	if p < PriorityLow {
+		p = PriorityLow
+	}
+	if p > PriorityHigh {
+		p = PriorityHigh
+	}
+	return func(o *fileOptions) { o.priority = p }
+}
+
+type job struct {
+	fn       func()
+	done     chan struct{}
+	started  bool
+	canceled bool
+}
+
+// opQueue runs operations one at a time on a single goroutine locked to
+// its OS thread, highest priority first and in order of submission within a priority.
+// A running operation is never preempted, a high priority operation
+// waits for it to finish. Long transfers are split into several operations
+// where the driver allows it, see Camera.DownloadTo.
+type opQueue struct {
+	mu      sync.Mutex
+	jobs    [PriorityHigh + 1][]*job
+	wake    chan struct{}
+	exited  chan struct{}
+	running bool
+}
+
+// do runs fn on the queue and waits for it to finish.
+// If ctx is done before fn started, fn is dropped and ctx.Err() returned.
+func (q *opQueue) do(ctx context.Context, p Priority, fn func()) error {
+	j := &job{fn: fn, done: make(chan struct{})}
+
+	q.mu.Lock()
+	if !q.running {
+		q.running = true
+		prev := q.exited
+		q.wake = make(chan struct{}, 1)
+		q.exited = make(chan struct{})
+		go q.work(q.wake, prev, q.exited)
+	}
+	q.jobs[p] = append(q.jobs[p], j)
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	if !j.started {
+		j.canceled = true
+		q.mu.Unlock()
+		return ctx.Err()
+	}
+	q.mu.Unlock()
+
+	// Already running, the cancel function of the GPContext takes care of it.
+	<-j.done
+	return nil
+}
+
+// stop ends the worker once all queued operations ran.
+// A later operation starts a new one.
+func (q *opQueue) stop() {
+	q.mu.Lock()
+	if q.running {
+		q.running = false
+		close(q.wake)
+	}
+	q.mu.Unlock()
+}
+
+func (q *opQueue) next() *job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for p := PriorityHigh; p >= PriorityLow; p-- {
+		for len(q.jobs[p]) != 0 {
+			j := q.jobs[p][0]
+			q.jobs[p][0] = nil
+			q.jobs[p] = q.jobs[p][1:]
+			if !j.canceled {
+				j.started = true
+				return j
+			}
+		}
+	}
+
+	return nil
+}
+
+func (q *opQueue) work(wake, prev, exited chan struct{}) {
+	defer close(exited)
+	if prev != nil {
+		// never run concurrently with a stopping worker
+		<-prev
+	}
+
+	// libgphoto2 and some of its port drivers are not thread aware,
+	// keep all calls on one thread.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	for {
+		for j := q.next(); j != nil; j = q.next() {
+			j.fn()
+			close(j.done)
+		}
+
+		if _, ok := <-wake; !ok {
+			return
+		}
+	}
+}
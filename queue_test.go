@@ -0,0 +1,106 @@
+package gphoto2go
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func (q *opQueue) queued() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, jobs := range q.jobs {
+		n += len(jobs)
+	}
+	return n
+}
+
+func waitQueued(t *testing.T, q *opQueue, n int) {
+	for i := 0; q.queued() != n; i++ {
+		if i == 1000 {
+			t.Fatalf("expected %d queued jobs, got %d", n, q.queued())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueuePriority(t *testing.T) {
+	q := new(opQueue)
+	defer q.stop()
+
+	release, started := make(chan struct{}), make(chan struct{})
+	go q.do(context.Background(), PriorityNormal, func() { close(started); <-release })
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	submit := func(name string, p Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.do(context.Background(), p, func() {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+			})
+		}()
+	}
+
+	submit("download", PriorityLow)
+	waitQueued(t, q, 1)
+	submit("list", PriorityNormal)
+	waitQueued(t, q, 2)
+	submit("preview", PriorityHigh)
+	waitQueued(t, q, 3)
+
+	close(release)
+	wg.Wait()
+
+	exp := []string{"preview", "list", "download"}
+	if !reflect.DeepEqual(order, exp) {
+		t.Errorf("expected order %v, got %v", exp, order)
+	}
+}
+
+func TestQueueCancelQueued(t *testing.T) {
+	q := new(opQueue)
+	defer q.stop()
+
+	release, started := make(chan struct{}), make(chan struct{})
+	go q.do(context.Background(), PriorityNormal, func() { close(started); <-release })
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := false
+	errs := make(chan error)
+	go func() { errs <- q.do(ctx, PriorityNormal, func() { ran = true }) }()
+	waitQueued(t, q, 1)
+
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	close(release)
+	if err := q.do(context.Background(), PriorityNormal, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if ran {
+		t.Error("expected the canceled job to be dropped")
+	}
+}
+
+func TestQueueRestart(t *testing.T) {
+	q := new(opQueue)
+	for i := 0; i < 3; i++ {
+		ran := false
+		if err := q.do(context.Background(), PriorityNormal, func() { ran = true }); err != nil || !ran {
+			t.Fatalf("expected job to run after restart %d: %v", i, err)
+		}
+		q.stop()
+	}
+}
@@ -0,0 +1,315 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"sync"
+	"unsafe"
+)
+
+// readSeekerChunk is the size of the reads of WriteTo.
+const readSeekerChunk = 1024 * 1024
+
+// errSizeUnknown is returned by Size for drivers not reporting file sizes.
+var errSizeUnknown = errors.New("file size unknown")
+
+var errReaderClosed = errors.New("can't read from closed reader")
+
+// fileSource does the partial reads of a ReadSeeker.
+type fileSource interface {
+	// readAt does a single read of at most len(p) bytes at off,
+	// returning 0 bytes at the end of the file.
+	readAt(ctx context.Context, p []byte, off int64) (int, error)
+	size(ctx context.Context) (int64, error)
+	close()
+}
+
+// ReadSeeker reads a file on the camera in parts, only transferring what is read.
+// It implements io.ReadSeeker, io.ReaderAt, io.WriterTo and io.Closer.
+type ReadSeeker struct {
+	ctx    context.Context
+	src    fileSource
+	offset int64
+
+	// mu is held for reading during reads, so Close waits for them.
+	mu     sync.RWMutex
+	closed bool
+
+	sizeMu  sync.Mutex
+	size    int64
+	sizeErr error
+}
+
+func newReadSeeker(ctx context.Context, src fileSource) *ReadSeeker {
+	return &ReadSeeker{ctx: ctx, src: src, size: -1}
+}
+
+// ReadSeeker returns a ReadSeeker of a file.
+// Uses gp_camera_file_read instead of gp_camera_file_get for increased performance.
+func (c *Camera) ReadSeeker(folder, file string) *ReadSeeker {
+	return c.ReadSeekerContext(context.Background(), folder, file)
+}
+
+// ReadSeekerContext is ReadSeeker with a context used for all its reads and options,
+// see WithFileType to read thumbnails and such.
+func (c *Camera) ReadSeekerContext(ctx context.Context, folder, file string, opts ...FileOption) *ReadSeeker {
+	o := newFileOptions(opts)
+	var src fileSource = newCameraSource(c, folder, file, o)
+	if bc := c.cache(); bc != nil {
+		src = bc.source(src, path.Join(folder, file), o.typ)
+	}
+
+	return newReadSeeker(ctx, src)
+}
+
+// Size returns the size of the file, it is looked up with Info on first use.
+// Reads do not depend on it, drivers not reporting sizes end files with an empty read.
+func (r *ReadSeeker) Size() (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return 0, errReaderClosed
+	}
+
+	return r.fileSize()
+}
+
+// sizeFinal reports whether looking up a size failed for good with err,
+// as the driver does not report sizes.
+func sizeFinal(err error) bool {
+	return errors.Is(err, errSizeUnknown) || isCode(err, ErrNotSupported)
+}
+
+// fileSize looks up the size once it succeeds or is known to be unavailable,
+// other errors, e.g. a canceled context, are retried.
+func (r *ReadSeeker) fileSize() (int64, error) {
+	r.sizeMu.Lock()
+	defer r.sizeMu.Unlock()
+	if r.sizeErr != nil {
+		return 0, r.sizeErr
+	}
+	if r.size >= 0 {
+		return r.size, nil
+	}
+
+	size, err := r.src.size(r.ctx)
+	if err != nil {
+		if sizeFinal(err) {
+			r.sizeErr = err
+		}
+		return 0, err
+	}
+
+	r.size = size
+	return size, nil
+}
+
+func (r *ReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	n := r.offset
+	switch whence {
+	case io.SeekStart:
+		n = offset
+	case io.SeekCurrent:
+		n += offset
+	case io.SeekEnd:
+		size, err := r.Size()
+		if err != nil {
+			return r.offset, err
+		}
+		n = size + offset
+	default:
+		return r.offset, errors.New("unknown whence")
+	}
+
+	if n < 0 {
+		return r.offset, errors.New("invalid negative offset")
+	}
+
+	r.offset = n
+	return r.offset, nil
+}
+
+// Read does a single read at the current offset,
+// which may return less than len(p) bytes before the end of the file.
+func (r *ReadSeeker) Read(p []byte) (int, error) {
+	n, err := r.read(p, r.offset)
+	r.offset += int64(n)
+	return n, err
+}
+
+// ReadAt reads len(p) bytes at off, it does not use or change the offset of Read.
+// It is safe for concurrent use.
+func (r *ReadSeeker) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("invalid negative offset")
+	}
+
+	var n int
+	for n < len(p) {
+		m, err := r.read(p[n:], off+int64(n))
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// WriteTo writes the remainder of the file to w.
+func (r *ReadSeeker) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, readSeekerChunk)
+	var n int64
+	for {
+		m, err := r.Read(buf)
+		if m > 0 {
+			written, werr := w.Write(buf[:m])
+			n += int64(written)
+			if werr != nil {
+				return n, werr
+			}
+			if written < m {
+				return n, io.ErrShortWrite
+			}
+		}
+
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// read does a single read of at most len(p) bytes at off, not past the size if known.
+// io.EOF is only returned when no bytes are left.
+func (r *ReadSeeker) read(p []byte, off int64) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return 0, errReaderClosed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if size, err := r.fileSize(); err == nil {
+		if off >= size {
+			return 0, io.EOF
+		}
+		if remaining := size - off; int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	n, err := r.src.readAt(r.ctx, p, off)
+	if err != nil {
+		return n, err
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
+
+// Close releases the file, it waits for running reads.
+func (r *ReadSeeker) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.src.close()
+	return nil
+}
+
+// cameraSource reads a file with gp_camera_file_read.
+type cameraSource struct {
+	c      *Camera
+	folder string
+	name   string
+	typ    FileType
+	p      Priority
+	// quiet drops the progress of the driver, for downloads reporting their own
+	quiet bool
+	dir   *C.char
+	file  *C.char
+}
+
+func newCameraSource(c *Camera, folder, file string, o fileOptions) *cameraSource {
+	return &cameraSource{
+		c:      c,
+		folder: folder,
+		name:   file,
+		typ:    o.typ,
+		p:      o.priority,
+		dir:    C.CString(folder),
+		file:   C.CString(file),
+	}
+}
+
+func (s *cameraSource) readAt(ctx context.Context, p []byte, off int64) (int, error) {
+	cSize := C.uint64_t(len(p))
+	cOffset := C.uint64_t(off)
+	buf := (*C.char)(unsafe.Pointer(&p[0]))
+	read := func() C.int {
+		return C.gp_camera_file_read(
+			s.c.camera,
+			s.dir,
+			s.file,
+			C.CameraFileType(s.typ),
+			cOffset,
+			buf,
+			&cSize,
+			s.c.context,
+		)
+	}
+	var err error
+	if s.quiet {
+		err = s.c.callChunk(ctx, s.p, read)
+	} else {
+		err = s.c.callFile(ctx, s.p, path.Join(s.folder, s.name), read)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return int(cSize), nil
+}
+
+func (s *cameraSource) size(ctx context.Context) (int64, error) {
+	info, err := s.c.fileInfo(ctx, s.folder, s.name)
+	if err != nil {
+		return 0, err
+	}
+
+	var fields C.CameraFileInfoFields
+	var size C.uint64_t
+	switch s.typ {
+	case FileNormal, FileRaw:
+		fields, size = info.file.fields, info.file.size
+	case FilePreview:
+		fields, size = info.preview.fields, info.preview.size
+	case FileAudio:
+		fields, size = info.audio.fields, info.audio.size
+	}
+	if fields&C.GP_FILE_INFO_SIZE == 0 {
+		return 0, errSizeUnknown
+	}
+
+	return int64(size), nil
+}
+
+func (s *cameraSource) close() {
+	C.free(unsafe.Pointer(s.dir))
+	C.free(unsafe.Pointer(s.file))
+}
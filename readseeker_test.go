@@ -0,0 +1,149 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newReadSeekerFake(t *testing.T, data string) *Fake {
+	t.Helper()
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddFile("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2", []byte(data))
+	f.SetReadLimit(3)
+	return f
+}
+
+func TestReadSeekerShortReads(t *testing.T) {
+	f := newReadSeekerFake(t, "0123456789")
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+
+	p := make([]byte, 8)
+	n, err := r.Read(p)
+	if n != 3 || err != nil {
+		t.Fatalf("expected a short read of 3 bytes, got %d, %v", n, err)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "3456789" {
+		t.Errorf("expected 3456789, got %q", data)
+	}
+
+	if n, err := r.Read(p); n != 0 || err != io.EOF {
+		t.Errorf("expected EOF, got %d, %v", n, err)
+	}
+}
+
+func TestReadSeekerSeekEnd(t *testing.T) {
+	f := newReadSeekerFake(t, "0123456789")
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+
+	off, err := r.Seek(-4, io.SeekEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if off != 6 {
+		t.Errorf("expected offset 6, got %d", off)
+	}
+
+	buf := new(bytes.Buffer)
+	if n, err := r.WriteTo(buf); err != nil || n != 4 {
+		t.Fatalf("expected to write 4 bytes, got %d, %v", n, err)
+	}
+	if buf.String() != "6789" {
+		t.Errorf("expected 6789, got %q", buf)
+	}
+}
+
+func TestReadSeekerReadAt(t *testing.T) {
+	f := newReadSeekerFake(t, "0123456789")
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+
+	p := make([]byte, 7)
+	n, err := r.ReadAt(p, 2)
+	if err != nil || string(p[:n]) != "2345678" {
+		t.Errorf("expected 2345678, got %q, %v", p[:n], err)
+	}
+
+	n, err = r.ReadAt(p, 5)
+	if err != io.EOF || string(p[:n]) != "56789" {
+		t.Errorf("expected 56789 and EOF, got %q, %v", p[:n], err)
+	}
+
+	if _, err := r.Read(p[:1]); err != nil || p[0] != '0' {
+		t.Errorf("expected ReadAt not to move the offset, read %q, %v", p[:1], err)
+	}
+}
+
+func TestReadSeekerServeContent(t *testing.T) {
+	f := newReadSeekerFake(t, strings.Repeat("x", 100)+"tail")
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+
+	req := httptest.NewRequest("GET", "/IMG_0001.CR2", nil)
+	req.Header.Set("Range", "bytes=-4")
+	rec := httptest.NewRecorder()
+	http.ServeContent(rec, req, "IMG_0001.CR2", time.Time{}, r)
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", rec.Code)
+	}
+	if rec.Body.String() != "tail" {
+		t.Errorf("expected tail, got %q", rec.Body)
+	}
+}
+
+func TestReadSeekerSizeRetry(t *testing.T) {
+	f := newReadSeekerFake(t, "0123456789")
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+	defer r.Close()
+
+	f.Fail("Info", newError(ErrIO))
+	if _, err := r.Size(); !isCode(err, ErrIO) {
+		t.Fatalf("expected ErrIO, got %v", err)
+	}
+
+	f.Fail("Info", nil)
+	if size, err := r.Size(); err != nil || size != 10 {
+		t.Errorf("expected the size to be looked up again, got %d, %v", size, err)
+	}
+}
+
+func TestReadSeekerCloseConcurrent(t *testing.T) {
+	f := newReadSeekerFake(t, "0123456789")
+	r := f.ReadSeeker("/store_00010001/DCIM/100FAKE", "IMG_0001.CR2")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := make([]byte, 4)
+			for j := 0; j < 100; j++ {
+				if _, err := r.ReadAt(p, 2); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	r.Close()
+	wg.Wait()
+
+	if _, err := r.ReadAt(make([]byte, 1), 0); err == nil {
+		t.Error("expected an error reading a closed reader")
+	}
+}
@@ -0,0 +1,280 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdlib.h>
+//
+// // Weak, as libgphoto2 before 2.5.10 does not have single configs.
+// extern int gp_camera_get_single_config(Camera *camera, const char *name, CameraWidget **widget, GPContext *context) __attribute__((weak));
+// extern int gp_camera_set_single_config(Camera *camera, const char *name, CameraWidget *widget, GPContext *context) __attribute__((weak));
+//
+// static int gphoto2go_get_single_config(Camera *camera, const char *name, CameraWidget **widget, GPContext *context) {
+// 	if (!gp_camera_get_single_config) {
+// 		return GP_ERROR_NOT_SUPPORTED;
+// 	}
+// 	return gp_camera_get_single_config(camera, name, widget, context);
+// }
+//
+// static int gphoto2go_set_single_config(Camera *camera, const char *name, CameraWidget *widget, GPContext *context) {
+// 	if (!gp_camera_set_single_config) {
+// 		return GP_ERROR_NOT_SUPPORTED;
+// 	}
+// 	return gp_camera_set_single_config(camera, name, widget, context);
+// }
+import "C"
+import (
+	"context"
+	"unsafe"
+)
+
+// GetSetting returns the value of a single configuration widget by name, e.g. "iso",
+// see CameraWidget.Value for its type.
+// Only that setting is read from the camera, instead of the whole configuration tree
+// like Update does, unless libgphoto2 or the driver does not support it.
+// The tree returned by Config is not touched either way.
+func (c *Camera) GetSetting(name string) (interface{}, error) {
+	return c.GetSettingContext(context.Background(), name)
+}
+
+// GetSettingContext is GetSetting with a context.
+func (c *Camera) GetSettingContext(ctx context.Context, name string) (interface{}, error) {
+	if err := c.initErr(); err != nil {
+		return nil, err
+	}
+
+	return getSetting(ctx, c, name)
+}
+
+// SetSetting sets a single configuration widget by name, see CameraWidget.SetValue for the types of value.
+// Only that setting is written to the camera, instead of all changed widgets like SetConfig does,
+// unless libgphoto2 or the driver does not support it.
+// The tree returned by Config is kept in sync.
+func (c *Camera) SetSetting(name string, value interface{}) error {
+	return c.SetSettingContext(context.Background(), name, value)
+}
+
+// SetSettingContext is SetSetting with a context.
+func (c *Camera) SetSettingContext(ctx context.Context, name string, value interface{}) error {
+	if err := c.initErr(); err != nil {
+		return err
+	}
+
+	return setSetting(ctx, c, name, value)
+}
+
+// singleConfig reads a single widget from the camera, it has to be freed.
+func (c *Camera) singleConfig(ctx context.Context, name string) (*CameraWidget, error) {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	w := new(CameraWidget)
+	err := c.call(ctx, func() C.int {
+		return C.gphoto2go_get_single_config(c.camera, cName, &w.widget, c.context)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (c *Camera) setSingleConfig(ctx context.Context, name string, w *CameraWidget) error {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	return c.call(ctx, func() C.int {
+		return C.gphoto2go_set_single_config(c.camera, cName, w.widget, c.context)
+	})
+}
+
+// readConfig reads a configuration tree of its own, unlike Update, it has to be freed.
+func (c *Camera) readConfig(ctx context.Context) (*CameraWidget, error) {
+	root := new(CameraWidget)
+	err := c.call(ctx, func() C.int { return C.gp_camera_get_config(c.camera, &root.widget, c.context) })
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+func (c *Camera) writeConfig(ctx context.Context, root *CameraWidget) error {
+	return c.call(ctx, func() C.int { return C.gp_camera_set_config(c.camera, root.widget, c.context) })
+}
+
+// withConfig calls fn with the tree returned by Config on the queue,
+// so Update does not free it meanwhile.
+func (c *Camera) withConfig(ctx context.Context, fn func(root *CameraWidget)) error {
+	return c.call(ctx, func() C.int {
+		if root := c.configTree(); root != nil {
+			fn(root)
+		}
+		return C.GP_OK
+	})
+}
+
+// settingBackend is what GetSetting and SetSetting need from a camera.
+type settingBackend interface {
+	// singleConfig reads a single widget, it has to be freed.
+	// It returns ErrNotSupported if only whole trees can be read.
+	singleConfig(ctx context.Context, name string) (*CameraWidget, error)
+	setSingleConfig(ctx context.Context, name string, w *CameraWidget) error
+	// readConfig reads a fresh configuration tree, it has to be freed.
+	readConfig(ctx context.Context) (*CameraWidget, error)
+	// writeConfig writes the changed widgets of a tree from readConfig.
+	writeConfig(ctx context.Context, root *CameraWidget) error
+	// withConfig calls fn with the tree returned by Config, if there is one.
+	withConfig(ctx context.Context, fn func(root *CameraWidget)) error
+}
+
+// getSetting reads the widget called name from b, from a tree of its own
+// if single widgets are not supported.
+func getSetting(ctx context.Context, b settingBackend, name string) (interface{}, error) {
+	w, err := b.singleConfig(ctx, name)
+	if err == nil {
+		defer w.Free()
+		return w.Value()
+	}
+	if !isCode(err, ErrNotSupported) {
+		return nil, err
+	}
+
+	root, err := b.readConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Free()
+	if w, err = root.Child(name); err != nil {
+		return nil, err
+	}
+
+	return w.Value()
+}
+
+// setSetting writes the widget called name to b, through a tree of its own
+// if single widgets are not supported, where it is the only changed widget.
+// The widget of the tree returned by Config is updated without marking it changed.
+func setSetting(ctx context.Context, b settingBackend, name string, value interface{}) error {
+	var written interface{}
+	w, err := b.singleConfig(ctx, name)
+	if err == nil {
+		defer w.Free()
+		if err := w.SetValue(value); err != nil {
+			return err
+		}
+		if written, err = w.Value(); err != nil {
+			return err
+		}
+		err = b.setSingleConfig(ctx, name, w)
+	}
+	if isCode(err, ErrNotSupported) {
+		var root *CameraWidget
+		if root, err = b.readConfig(ctx); err != nil {
+			return err
+		}
+		defer root.Free()
+
+		if w, err = root.Child(name); err != nil {
+			return err
+		}
+		if err := w.SetValue(value); err != nil {
+			return err
+		}
+		if written, err = w.Value(); err != nil {
+			return err
+		}
+		err = b.writeConfig(ctx, root)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Mirror the value without having SetConfig write it again.
+	return b.withConfig(ctx, func(root *CameraWidget) {
+		if child, err := root.Child(name); err == nil && child.setValue(written) == nil {
+			child.SetChanged(false)
+		}
+	})
+}
+
+// GetSetting is Camera.GetSetting, reading the configuration tree.
+func (f *Fake) GetSetting(name string) (interface{}, error) {
+	if err := f.fail("GetSetting"); err != nil {
+		return nil, err
+	}
+
+	return getSetting(context.Background(), f, name)
+}
+
+// SetSetting is Camera.SetSetting, setting a widget of the configuration tree.
+func (f *Fake) SetSetting(name string, value interface{}) error {
+	if err := f.fail("SetSetting"); err != nil {
+		return err
+	}
+
+	return setSetting(context.Background(), f, name, value)
+}
+
+// singleConfig copies the widget called name, the configuration tree is both
+// the state of the camera and the tree returned by Config.
+func (f *Fake) singleConfig(_ context.Context, name string) (*CameraWidget, error) {
+	w, err := f.config.Child(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.clone()
+}
+
+func (f *Fake) setSingleConfig(_ context.Context, name string, w *CameraWidget) error {
+	target, err := f.config.Child(name)
+	if err != nil {
+		return err
+	}
+
+	return f.store(target, w)
+}
+
+func (f *Fake) readConfig(context.Context) (*CameraWidget, error) {
+	return f.config.clone()
+}
+
+func (f *Fake) writeConfig(_ context.Context, root *CameraWidget) error {
+	return root.Walk(func(path string, w *CameraWidget) error {
+		if changed, err := w.Changed(); err != nil || !changed {
+			return err
+		}
+
+		target, err := f.config.ChildByPath(path)
+		if err != nil {
+			return err
+		}
+		return f.store(target, w)
+	})
+}
+
+func (f *Fake) withConfig(_ context.Context, fn func(root *CameraWidget)) error {
+	fn(f.config)
+	return nil
+}
+
+// store writes the value of w to the widget target of the configuration tree
+// like SetConfig does.
+func (f *Fake) store(target, w *CameraWidget) error {
+	v, err := w.Value()
+	if err != nil {
+		return err
+	}
+	if err := target.setValue(v); err != nil {
+		return err
+	}
+	path, err := target.Path()
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.written = append(f.written, path)
+	f.mu.Unlock()
+	return target.SetChanged(false)
+}
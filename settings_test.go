@@ -0,0 +1,129 @@
+package gphoto2go
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFakeSetting(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, _ := f.Config()
+	iso, err := NewWidget(WidgetRadio, "iso", "ISO Speed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []string{"100", "200", "400"} {
+		if err := iso.AddChoice(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := root.Append(iso); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.SetSetting("iso", "400"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := f.GetSetting("iso"); err != nil || v != "400" {
+		t.Errorf("expected 400, got %v %v", v, err)
+	}
+	if err := f.SetSetting("iso", 400); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	if _, err := f.GetSetting("shutterspeed"); err == nil {
+		t.Error("expected an error for a missing setting")
+	}
+
+	f.Fail("SetSetting", newError(ErrNotSupported))
+	if err := f.SetSetting("iso", "100"); !isCode(err, ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
+
+// stagedBackend is a camera whose state is a Fake,
+// with a tree returned by Config of its own.
+type stagedBackend struct {
+	*Fake
+	staged   *CameraWidget
+	noSingle bool
+}
+
+func (b *stagedBackend) singleConfig(ctx context.Context, name string) (*CameraWidget, error) {
+	if b.noSingle {
+		return nil, newError(ErrNotSupported)
+	}
+	return b.Fake.singleConfig(ctx, name)
+}
+
+func (b *stagedBackend) withConfig(_ context.Context, fn func(*CameraWidget)) error {
+	fn(b.staged)
+	return nil
+}
+
+func TestSettingStaged(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := f.Config()
+	section, _ := NewWidget(WidgetSection, "settings", "Settings")
+	iso, _ := NewWidget(WidgetRadio, "iso", "ISO Speed")
+	for _, c := range []string{"100", "200", "400"} {
+		iso.AddChoice(c)
+	}
+	iso.SetString("100")
+	owner, _ := NewWidget(WidgetText, "ownername", "Owner Name")
+	section.Append(iso)
+	section.Append(owner)
+	root.Append(section)
+
+	staged, err := root.clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer staged.Free()
+	if w, _ := staged.Child("ownername"); w.SetValue("me") != nil {
+		t.Fatal("expected to stage the ownername")
+	}
+
+	ctx := context.Background()
+	for _, noSingle := range []bool{true, false} {
+		b := &stagedBackend{Fake: f, staged: staged, noSingle: noSingle}
+		f.written = nil
+		value := "400"
+		if !noSingle {
+			value = "200"
+		}
+
+		if err := setSetting(ctx, b, "iso", value); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(f.written, []string{"/settings/iso"}) {
+			t.Errorf("single %v: expected only iso to be written, got %v", !noSingle, f.written)
+		}
+		if v, err := getSetting(ctx, b, "iso"); err != nil || v != value {
+			t.Errorf("single %v: expected %s, got %v %v", !noSingle, value, v, err)
+		}
+
+		w, _ := staged.Child("iso")
+		if v, _ := w.Value(); v != value {
+			t.Errorf("single %v: expected the staged iso to be %s, got %v", !noSingle, value, v)
+		}
+		if changed, _ := w.Changed(); changed {
+			t.Errorf("single %v: expected the staged iso to be unchanged", !noSingle)
+		}
+		w, _ = staged.Child("ownername")
+		if changed, _ := w.Changed(); !changed {
+			t.Errorf("single %v: expected the staged ownername to stay changed", !noSingle)
+		}
+		if v, _ := owner.Value(); v != "" {
+			t.Errorf("single %v: expected the ownername not to be written, got %v", !noSingle, v)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package gphoto2go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+)
+
+// restoreFirst are settings other settings depend on, restored before the rest.
+// E.g. the shutter speed and aperture are only writable in manual exposure modes
+// and the color temperature only with a Kelvin white balance.
+var restoreFirst = []string{
+	"autoexposuremode",
+	"autoexposuremodedial",
+	"expprogram",
+	"exposuremode",
+	"capturemode",
+	"focusmode",
+	"isoauto",
+	"whitebalance",
+	"imageformat",
+	"imagequality",
+}
+
+// ConfigSetting is a setting of a ConfigSnapshot.
+type ConfigSetting struct {
+	// Path of the widget, e.g. "/main/imgsettings/iso"
+	Path string `json:"path"`
+	// Type of the widget, e.g. "Radio", see WidgetTypeInfo.Str
+	Type string `json:"type"`
+	// Value as returned by CameraWidget.Value
+	Value    interface{} `json:"value"`
+	Choices  []string    `json:"choices,omitempty"`
+	Readonly bool        `json:"readonly,omitempty"`
+}
+
+// UnmarshalJSON decodes the value to the Go type of the widget type.
+func (s *ConfigSetting) UnmarshalJSON(data []byte) error {
+	type setting ConfigSetting
+	var raw struct {
+		setting
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = ConfigSetting(raw.setting)
+
+	var err error
+	switch s.Type {
+	case "Range":
+		var v float64
+		err = json.Unmarshal(raw.Value, &v)
+		s.Value = v
+	case "Toggle":
+		var v int
+		err = json.Unmarshal(raw.Value, &v)
+		s.Value = v
+	case "Date":
+		var v time.Time
+		err = json.Unmarshal(raw.Value, &v)
+		s.Value = v
+	default:
+		var v string
+		err = json.Unmarshal(raw.Value, &v)
+		s.Value = v
+	}
+	if err != nil {
+		return fmt.Errorf("setting %s: %w", s.Path, err)
+	}
+
+	return nil
+}
+
+// ConfigSnapshot holds every setting of a configuration tree,
+// e.g. to save it as JSON and Restore it on another day or another camera.
+type ConfigSnapshot struct {
+	Settings []ConfigSetting `json:"settings"`
+}
+
+// SnapshotConfig takes a snapshot of the configuration returned by b.Config.
+func SnapshotConfig(b Backend) (*ConfigSnapshot, error) {
+	root, err := b.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConfigSnapshot(root)
+}
+
+// NewConfigSnapshot takes a snapshot of all widgets below root that have a value.
+func NewConfigSnapshot(root *CameraWidget) (*ConfigSnapshot, error) {
+	s := &ConfigSnapshot{Settings: []ConfigSetting{}}
+	err := root.Walk(func(_ string, w *CameraWidget) error {
+		typ, err := w.Type()
+		if err != nil {
+			return err
+		}
+		if typ.vtype == wvtWeird {
+			return nil
+		}
+
+		setting := ConfigSetting{Type: typ.Str()}
+		if setting.Path, err = w.Path(); err != nil {
+			return err
+		}
+		if setting.Value, err = w.Value(); err != nil {
+			return fmt.Errorf("setting %s: %w", setting.Path, err)
+		}
+		if setting.Choices, err = w.Choices(); err != nil {
+			return err
+		}
+		if len(setting.Choices) == 0 {
+			setting.Choices = nil
+		}
+		if setting.Readonly, err = w.Readonly(); err != nil {
+			return err
+		}
+
+		s.Settings = append(s.Settings, setting)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// RestoreOptions configures ConfigSnapshot.Restore.
+type RestoreOptions struct {
+	// DryRun only validates the settings that would be changed, on a copy of the current configuration.
+	// The settings others depend on are applied to the copy first, but what the camera derives from them,
+	// like which settings are writable, is only known once they are written:
+	// the results of the other settings are provisional.
+	DryRun bool
+}
+
+// RestoreStatus is the outcome of restoring a setting.
+type RestoreStatus int
+
+// Restore statuses
+const (
+	// RestoreApplied settings were changed, or would have been in a dry run
+	RestoreApplied RestoreStatus = iota
+	// RestoreUnchanged settings already had the value of the snapshot
+	RestoreUnchanged
+	// RestoreSkipped settings are read-only, in the snapshot or on the camera
+	RestoreSkipped
+	// RestoreFailed settings are missing or could not be set, see RestoreResult.Err
+	RestoreFailed
+)
+
+func (s RestoreStatus) String() string {
+	switch s {
+	case RestoreApplied:
+		return "applied"
+	case RestoreUnchanged:
+		return "unchanged"
+	case RestoreSkipped:
+		return "skipped"
+	case RestoreFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("RestoreStatus(%d)", int(s))
+}
+
+// RestoreResult is the outcome of restoring a single setting.
+type RestoreResult struct {
+	Path   string
+	Status RestoreStatus
+	// Previous is the value before restoring
+	Previous interface{}
+	Value    interface{}
+	Err      error
+}
+
+type contextSettingSetter interface {
+	SetSettingContext(ctx context.Context, name string, value interface{}) error
+}
+
+type settingSetter interface {
+	SetSetting(name string, value interface{}) error
+}
+
+type contextConfigurer interface {
+	UpdateContext(ctx context.Context) error
+	SetConfigContext(ctx context.Context) error
+}
+
+// Restore applies the writable settings of the snapshot to b, one at a time,
+// settings others depend on, like the exposure mode, first.
+// The configuration is refreshed with Update after those, as they change what else is writable.
+// Settings are set with SetSetting if b supports it, otherwise with SetConfig.
+// The returned error is only about reading the configuration,
+// the result of each setting is reported by its RestoreResult.
+func (s *ConfigSnapshot) Restore(ctx context.Context, b Backend, opts RestoreOptions) ([]RestoreResult, error) {
+	first := make(map[string]int, len(restoreFirst))
+	for i, name := range restoreFirst {
+		first[name] = i
+	}
+
+	var early, late []ConfigSetting
+	for _, setting := range s.Settings {
+		if _, ok := first[path.Base(setting.Path)]; ok {
+			early = append(early, setting)
+			continue
+		}
+		late = append(late, setting)
+	}
+	// Sort early settings by restoreFirst, keeping the snapshot order otherwise.
+	sort.SliceStable(early, func(i, j int) bool {
+		return first[path.Base(early[i].Path)] < first[path.Base(early[j].Path)]
+	})
+
+	results := make([]RestoreResult, 0, len(s.Settings))
+	var root *CameraWidget
+	if opts.DryRun {
+		config, err := b.Config()
+		if err != nil {
+			return results, err
+		}
+		if root, err = config.clone(); err != nil {
+			return results, err
+		}
+		defer root.Free()
+	}
+
+	for i, settings := range [][]ConfigSetting{early, late} {
+		if !opts.DryRun {
+			if i == 1 && len(early) != 0 {
+				if err := update(ctx, b); err != nil {
+					return results, err
+				}
+			}
+
+			var err error
+			if root, err = b.Config(); err != nil {
+				return results, err
+			}
+		}
+
+		for _, setting := range settings {
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+			results = append(results, restore(ctx, b, root, setting, opts.DryRun))
+		}
+	}
+
+	return results, nil
+}
+
+// restore applies a single setting to b whose configuration tree is root.
+func restore(ctx context.Context, b Backend, root *CameraWidget, setting ConfigSetting, dryRun bool) RestoreResult {
+	r := RestoreResult{Path: setting.Path, Value: setting.Value}
+	fail := func(err error) RestoreResult {
+		r.Status, r.Err = RestoreFailed, err
+		return r
+	}
+
+	if setting.Readonly {
+		r.Status = RestoreSkipped
+		return r
+	}
+
+	w, err := root.ChildByPath(setting.Path)
+	if err != nil {
+		return fail(err)
+	}
+	if ro, err := w.Readonly(); err != nil {
+		return fail(err)
+	} else if ro {
+		r.Status = RestoreSkipped
+		return r
+	}
+	if r.Previous, err = w.Value(); err != nil {
+		return fail(err)
+	}
+	if valueEqual(r.Previous, setting.Value) {
+		r.Status = RestoreUnchanged
+		return r
+	}
+
+	if dryRun {
+		// root is a copy, keep the value for the settings depending on it.
+		if err := w.SetValue(setting.Value); err != nil {
+			return fail(err)
+		}
+		return r
+	}
+
+	name, err := w.Name()
+	if err != nil {
+		return fail(err)
+	}
+	if err := setConfig(ctx, b, w, name, setting.Value); err != nil {
+		return fail(err)
+	}
+
+	return r
+}
+
+// setConfig sets the widget w called name of b to value.
+func setConfig(ctx context.Context, b Backend, w *CameraWidget, name string, value interface{}) error {
+	switch s := b.(type) {
+	case contextSettingSetter:
+		return s.SetSettingContext(ctx, name, value)
+	case settingSetter:
+		return s.SetSetting(name, value)
+	}
+
+	if err := w.SetValue(value); err != nil {
+		return err
+	}
+	if c, ok := b.(contextConfigurer); ok {
+		return c.SetConfigContext(ctx)
+	}
+	return b.SetConfig()
+}
+
+func update(ctx context.Context, b Backend) error {
+	if c, ok := b.(contextConfigurer); ok {
+		return c.UpdateContext(ctx)
+	}
+	return b.Update()
+}
+
+func valueEqual(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return a == b
+}
@@ -0,0 +1,191 @@
+package gphoto2go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func snapshotFake(t *testing.T) *Fake {
+	t.Helper()
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := f.Config()
+
+	widget := func(parent *CameraWidget, typ WidgetType, name string) *CameraWidget {
+		w, err := NewWidget(typ, name, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := parent.Append(w); err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+
+	capture := widget(root, WidgetSection, "capturesettings")
+	shutter := widget(capture, WidgetRadio, "shutterspeed")
+	mode := widget(capture, WidgetRadio, "autoexposuremode")
+	zoom := widget(capture, WidgetRange, "zoom")
+	af := widget(capture, WidgetToggle, "autofocus")
+	settings := widget(root, WidgetSection, "settings")
+	date := widget(settings, WidgetDate, "datetime")
+	serial := widget(settings, WidgetText, "serialnumber")
+	widget(settings, WidgetButton, "autofocusdrive")
+
+	for _, c := range []string{"1/100", "1/200"} {
+		shutter.AddChoice(c)
+	}
+	for _, c := range []string{"P", "M"} {
+		mode.AddChoice(c)
+	}
+	if err := zoom.SetRange(0, 10, 1); err != nil {
+		t.Fatal(err)
+	}
+	for w, v := range map[*CameraWidget]interface{}{
+		shutter: "1/100",
+		mode:    "M",
+		zoom:    3,
+		af:      true,
+		date:    time.Unix(1700000000, 0),
+		serial:  "1234",
+	} {
+		if err := w.SetValue(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := serial.SetReadonly(true); err != nil {
+		t.Fatal(err)
+	}
+
+	return f
+}
+
+func TestConfigSnapshot(t *testing.T) {
+	f := snapshotFake(t)
+	s, err := SnapshotConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(ConfigSnapshot)
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	for i := range s.Settings {
+		if v, ok := s.Settings[i].Value.(time.Time); ok {
+			if !v.Equal(decoded.Settings[i].Value.(time.Time)) {
+				t.Errorf("expected %v, got %v", v, decoded.Settings[i].Value)
+			}
+			decoded.Settings[i].Value = v
+		}
+	}
+	if !reflect.DeepEqual(decoded, s) {
+		t.Errorf("expected %+v, got %+v", s, decoded)
+	}
+
+	expect := []ConfigSetting{
+		{Path: "/capturesettings/shutterspeed", Type: "Radio", Value: "1/100", Choices: []string{"1/100", "1/200"}},
+		{Path: "/capturesettings/autoexposuremode", Type: "Radio", Value: "M", Choices: []string{"P", "M"}},
+		{Path: "/capturesettings/zoom", Type: "Range", Value: 3.0},
+		{Path: "/capturesettings/autofocus", Type: "Toggle", Value: 1},
+		{Path: "/settings/datetime", Type: "Date", Value: time.Unix(1700000000, 0)},
+		{Path: "/settings/serialnumber", Type: "Text", Value: "1234", Readonly: true},
+	}
+	if !reflect.DeepEqual(s.Settings, expect) {
+		t.Errorf("expected %+v, got %+v", expect, s.Settings)
+	}
+}
+
+func TestConfigRestore(t *testing.T) {
+	f := snapshotFake(t)
+	s, err := SnapshotConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Settings = append(s.Settings,
+		ConfigSetting{Path: "/settings/missing", Type: "Text", Value: "x"},
+		ConfigSetting{Path: "/capturesettings/zoom", Type: "Range", Value: 11.0},
+	)
+
+	for name, v := range map[string]interface{}{"shutterspeed": "1/200", "autoexposuremode": "P", "zoom": 5} {
+		if err := f.SetSetting(name, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status := func(results []RestoreResult) map[string]RestoreStatus {
+		m := make(map[string]RestoreStatus)
+		for _, r := range results {
+			if r.Status == RestoreFailed && r.Err == nil {
+				t.Errorf("%s: failed without an error", r.Path)
+			}
+			m[r.Path] = r.Status
+		}
+		return m
+	}
+
+	results, err := s.Restore(context.Background(), f, RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(s.Settings) {
+		t.Fatalf("expected %d results, got %d", len(s.Settings), len(results))
+	}
+	if results[0].Path != "/capturesettings/autoexposuremode" {
+		t.Errorf("expected the exposure mode first, got %s", results[0].Path)
+	}
+	if results[len(results)-1].Status != RestoreFailed || !errors.Is(results[len(results)-1].Err, ErrInvalidValue) {
+		t.Errorf("expected an invalid zoom to fail, got %+v", results[len(results)-1])
+	}
+	expect := map[string]RestoreStatus{
+		"/capturesettings/autoexposuremode": RestoreApplied,
+		"/capturesettings/shutterspeed":     RestoreApplied,
+		"/capturesettings/zoom":             RestoreFailed,
+		"/capturesettings/autofocus":        RestoreUnchanged,
+		"/settings/datetime":                RestoreUnchanged,
+		"/settings/serialnumber":            RestoreSkipped,
+		"/settings/missing":                 RestoreFailed,
+	}
+	if got := status(results); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+	if v, _ := f.GetSetting("shutterspeed"); v != "1/200" {
+		t.Errorf("dry run changed the shutter speed to %v", v)
+	}
+	root, _ := f.Config()
+	for _, p := range []string{"/capturesettings/autoexposuremode", "/capturesettings/shutterspeed"} {
+		w, _ := root.ChildByPath(p)
+		if changed, _ := w.Changed(); changed {
+			t.Errorf("dry run marked %s changed", p)
+		}
+	}
+	if s := RestoreStatus(9).String(); s != "RestoreStatus(9)" {
+		t.Errorf("expected RestoreStatus(9), got %s", s)
+	}
+
+	// Without the invalid zoom the zoom is restored too.
+	s.Settings = s.Settings[:len(s.Settings)-1]
+	results, err = s.Restore(context.Background(), f, RestoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect["/capturesettings/zoom"] = RestoreApplied
+	if got := status(results); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+	for name, v := range map[string]interface{}{"shutterspeed": "1/100", "autoexposuremode": "M", "zoom": 3.0} {
+		if got, _ := f.GetSetting(name); got != v {
+			t.Errorf("%s: expected %v, got %v", name, v, got)
+		}
+	}
+}
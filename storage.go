@@ -0,0 +1,190 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"context"
+	"unsafe"
+)
+
+// StorageFields flags the fields of a Storage the driver reported, the others are zero.
+type StorageFields int
+
+const (
+	StorageInfoBase           StorageFields = C.GP_STORAGEINFO_BASE
+	StorageInfoLabel          StorageFields = C.GP_STORAGEINFO_LABEL
+	StorageInfoDescription    StorageFields = C.GP_STORAGEINFO_DESCRIPTION
+	StorageInfoAccess         StorageFields = C.GP_STORAGEINFO_ACCESS
+	StorageInfoType           StorageFields = C.GP_STORAGEINFO_STORAGETYPE
+	StorageInfoFilesystemType StorageFields = C.GP_STORAGEINFO_FILESYSTEMTYPE
+	StorageInfoCapacity       StorageFields = C.GP_STORAGEINFO_MAXCAPACITY
+	StorageInfoFree           StorageFields = C.GP_STORAGEINFO_FREESPACEKBYTES
+	StorageInfoFreeImages     StorageFields = C.GP_STORAGEINFO_FREESPACEIMAGES
+)
+
+// Has reports whether all fields of f2 are set.
+func (f StorageFields) Has(f2 StorageFields) bool {
+	return f&f2 == f2
+}
+
+// StorageType is the kind of medium of a Storage.
+type StorageType int
+
+const (
+	StorageUnknown      StorageType = C.GP_STORAGEINFO_ST_UNKNOWN
+	StorageFixedROM     StorageType = C.GP_STORAGEINFO_ST_FIXED_ROM
+	StorageRemovableROM StorageType = C.GP_STORAGEINFO_ST_REMOVABLE_ROM
+	StorageFixedRAM     StorageType = C.GP_STORAGEINFO_ST_FIXED_RAM
+	// StorageRemovableRAM is a memory card.
+	StorageRemovableRAM StorageType = C.GP_STORAGEINFO_ST_REMOVABLE_RAM
+)
+
+// StorageAccess are the access rights of a Storage.
+type StorageAccess int
+
+const (
+	AccessReadWrite          StorageAccess = C.GP_STORAGEINFO_AC_READWRITE
+	AccessReadOnly           StorageAccess = C.GP_STORAGEINFO_AC_READONLY
+	AccessReadOnlyWithDelete StorageAccess = C.GP_STORAGEINFO_AC_READONLY_WITH_DELETE
+)
+
+// FilesystemType is the layout of the filesystem of a Storage.
+type FilesystemType int
+
+const (
+	FilesystemUndefined    FilesystemType = C.GP_STORAGEINFO_FST_UNDEFINED
+	FilesystemFlat         FilesystemType = C.GP_STORAGEINFO_FST_GENERICFLAT
+	FilesystemHierarchical FilesystemType = C.GP_STORAGEINFO_FST_GENERICHIERARCHICAL
+	// FilesystemDCF is the Design rule for Camera File system, e.g. DCIM/100CANON.
+	FilesystemDCF FilesystemType = C.GP_STORAGEINFO_FST_DCF
+)
+
+// Storage describes a storage of the camera, like a memory card slot.
+type Storage struct {
+	Fields StorageFields
+	// Base is the folder of the storage, e.g. "/store_00010001".
+	Base           string
+	Label          string
+	Description    string
+	Type           StorageType
+	FilesystemType FilesystemType
+	Access         StorageAccess
+	// Capacity and Free are in bytes.
+	Capacity int64
+	Free     int64
+	// FreeImages is the camera's estimate of the number of images that still fit.
+	FreeImages int64
+}
+
+// Storages returns the storages of the camera.
+func (c *Camera) Storages() ([]Storage, error) {
+	return c.StoragesContext(context.Background())
+}
+
+// StoragesContext is Storages with a context.
+func (c *Camera) StoragesContext(ctx context.Context) ([]Storage, error) {
+	var sifs *C.CameraStorageInformation
+	var n C.int
+	if err := c.call(ctx, func() C.int {
+		return C.gp_camera_get_storageinfo(c.camera, &sifs, &n, c.context)
+	}); err != nil {
+		return nil, err
+	}
+	defer C.free(unsafe.Pointer(sifs))
+
+	storages := make([]Storage, 0, int(n))
+	for _, sif := range unsafe.Slice(sifs, int(n)) {
+		storages = append(storages, newStorage(&sif))
+	}
+
+	return storages, nil
+}
+
+// newStorage converts sif, leaving the fields sif.fields doesn't flag zero.
+func newStorage(sif *C.CameraStorageInformation) Storage {
+	s := Storage{Fields: StorageFields(sif.fields)}
+	if s.Fields.Has(StorageInfoBase) {
+		s.Base = C.GoString(&sif.basedir[0])
+	}
+	if s.Fields.Has(StorageInfoLabel) {
+		s.Label = C.GoString(&sif.label[0])
+	}
+	if s.Fields.Has(StorageInfoDescription) {
+		s.Description = C.GoString(&sif.description[0])
+	}
+	if s.Fields.Has(StorageInfoType) {
+		s.Type = StorageType(sif._type)
+	}
+	if s.Fields.Has(StorageInfoFilesystemType) {
+		s.FilesystemType = FilesystemType(sif.fstype)
+	}
+	if s.Fields.Has(StorageInfoAccess) {
+		s.Access = StorageAccess(sif.access)
+	}
+	if s.Fields.Has(StorageInfoCapacity) {
+		s.Capacity = int64(sif.capacitykbytes) * 1024
+	}
+	if s.Fields.Has(StorageInfoFree) {
+		s.Free = int64(sif.freekbytes) * 1024
+	}
+	if s.Fields.Has(StorageInfoFreeImages) {
+		s.FreeImages = int64(sif.freeimages)
+	}
+
+	return s
+}
+
+// rawStorage holds the values of a C.CameraStorageInformation,
+// it lets tests, which can't use cgo, go through newStorage.
+type rawStorage struct {
+	fields                         StorageFields
+	base, label, description       string
+	typ, fstype, access            int
+	capacityKB, freeKB, freeImages uint64
+}
+
+func (r rawStorage) storage() Storage {
+	var sif C.CameraStorageInformation
+	sif.fields = C.CameraStorageInfoFields(r.fields)
+	copyCString(sif.basedir[:], r.base)
+	copyCString(sif.label[:], r.label)
+	copyCString(sif.description[:], r.description)
+	sif._type = C.CameraStorageType(r.typ)
+	sif.fstype = C.CameraStorageFilesystemType(r.fstype)
+	sif.access = C.CameraStorageAccessType(r.access)
+	sif.capacitykbytes = C.uint64_t(r.capacityKB)
+	sif.freekbytes = C.uint64_t(r.freeKB)
+	sif.freeimages = C.uint64_t(r.freeImages)
+	return newStorage(&sif)
+}
+
+// copyCString copies s into the NUL terminated buf, truncating it if needed.
+func copyCString(buf []C.char, s string) {
+	if len(s) >= len(buf) {
+		s = s[:len(buf)-1]
+	}
+	for i := 0; i < len(s); i++ {
+		buf[i] = C.char(s[i])
+	}
+	buf[len(s)] = 0
+}
+
+// SetStorages sets the storages returned by Storages.
+func (f *Fake) SetStorages(storages []Storage) {
+	f.mu.Lock()
+	f.storages = append([]Storage(nil), storages...)
+	f.mu.Unlock()
+}
+
+// Storages returns the storages set with SetStorages.
+func (f *Fake) Storages() ([]Storage, error) {
+	if err := f.fail("Storages"); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Storage{}, f.storages...), nil
+}
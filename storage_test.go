@@ -0,0 +1,91 @@
+package gphoto2go
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFakeStorages(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storages, err := f.Storages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storages) != 0 {
+		t.Errorf("expected no storages, got %+v", storages)
+	}
+
+	exp := []Storage{
+		{
+			Fields:     StorageInfoBase | StorageInfoLabel | StorageInfoType | StorageInfoCapacity | StorageInfoFree,
+			Base:       "/store_00010001",
+			Label:      "SD",
+			Type:       StorageRemovableRAM,
+			Capacity:   64 << 30,
+			Free:       10 << 30,
+			FreeImages: 0,
+		},
+		{Fields: StorageInfoBase, Base: "/store_00020001"},
+	}
+	f.SetStorages(exp)
+	storages, err = f.Storages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(storages, exp) {
+		t.Errorf("expected %+v, got %+v", exp, storages)
+	}
+
+	if storages[0].Fields.Has(StorageInfoFreeImages) {
+		t.Error("expected the free image estimate to be unknown")
+	}
+	if !storages[0].Fields.Has(StorageInfoCapacity | StorageInfoFree) {
+		t.Error("expected the capacity and free space to be known")
+	}
+}
+
+func TestNewStorage(t *testing.T) {
+	raw := rawStorage{
+		fields:      StorageInfoBase | StorageInfoLabel | StorageInfoAccess | StorageInfoType | StorageInfoCapacity | StorageInfoFree,
+		base:        "/store_00010001",
+		label:       "SD",
+		description: "not reported",
+		typ:         int(StorageRemovableRAM),
+		fstype:      int(FilesystemDCF),
+		access:      int(AccessReadOnlyWithDelete),
+		capacityKB:  64 << 20,
+		freeKB:      10 << 20,
+		freeImages:  1234,
+	}
+
+	exp := Storage{
+		Fields:   raw.fields,
+		Base:     "/store_00010001",
+		Label:    "SD",
+		Type:     StorageRemovableRAM,
+		Access:   AccessReadOnlyWithDelete,
+		Capacity: 64 << 30,
+		Free:     10 << 30,
+	}
+	if s := raw.storage(); s != exp {
+		t.Errorf("expected %+v, got %+v", exp, s)
+	}
+
+	raw.fields |= StorageInfoDescription | StorageInfoFilesystemType | StorageInfoFreeImages
+	exp.Fields = raw.fields
+	exp.Description = "not reported"
+	exp.FilesystemType = FilesystemDCF
+	exp.FreeImages = 1234
+	if s := raw.storage(); s != exp {
+		t.Errorf("expected %+v, got %+v", exp, s)
+	}
+
+	raw.fields = 0
+	if s := raw.storage(); s != (Storage{}) {
+		t.Errorf("expected a zero storage without fields, got %+v", s)
+	}
+}
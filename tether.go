@@ -0,0 +1,235 @@
+package gphoto2go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultTetherTemplate   = "{{.Name}}"
+	defaultTetherPairWindow = 5 * time.Second
+)
+
+// TetherOptions configure a TetherSession.
+type TetherOptions struct {
+	// Dir is the local directory files are downloaded to, it is created if needed.
+	Dir string
+	// Template is a text/template producing the local file name from a TetherFile,
+	// "{{.Name}}" if empty. It may contain slashes to create subdirectories, e.g.
+	// `{{.Time.Format "2006-01-02"}}/shot_{{printf "%04d" .Seq}}{{.Ext}}`.
+	Template string
+	// Delete removes files from the camera once downloaded.
+	Delete bool
+	// PairWindow is how long after a file another one with the same base name
+	// is considered part of the same shot, like the JPEG of a RAW+JPEG capture.
+	// Defaults to 5 seconds.
+	PairWindow time.Duration
+}
+
+// TetherFile describes a file added on the camera.
+type TetherFile struct {
+	Folder string
+	Name   string
+	// Base and Ext are Name split before its extension, e.g. "IMG_0001" and ".CR2".
+	Base string
+	Ext  string
+	// Seq numbers the shots of the session starting from 1.
+	// All files of a shot, e.g. a RAW+JPEG pair, share the same Seq.
+	Seq int
+	// Time the camera reported the file.
+	Time time.Time
+}
+
+// TetherResult reports the download of a single file.
+type TetherResult struct {
+	TetherFile
+	// Path of the downloaded file. Existing files are not overwritten,
+	// a suffix like "-1" is added before the extension instead.
+	Path string
+	Size int64
+	// Err is set if downloading or deleting the file failed.
+	Err error
+}
+
+type tetherShot struct {
+	seq  int
+	seen time.Time
+}
+
+// TetherSession downloads every file added on a camera, whether captured
+// with TriggerCapture or with the shutter button on the camera itself.
+type TetherSession struct {
+	// Results receives a result for each file and is closed when the session ends.
+	Results <-chan TetherResult
+
+	b     Backend
+	opts  TetherOptions
+	tmpl  *template.Template
+	shots map[string]tetherShot
+	seq   int
+
+	done chan struct{}
+	err  error
+}
+
+// StartTether starts downloading all files added on b until ctx is done.
+func StartTether(ctx context.Context, b Backend, opts TetherOptions) (*TetherSession, error) {
+	if opts.Template == "" {
+		opts.Template = defaultTetherTemplate
+	}
+	if opts.PairWindow <= 0 {
+		opts.PairWindow = defaultTetherPairWindow
+	}
+
+	tmpl, err := template.New("tether").Parse(opts.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(chan TetherResult)
+	s := &TetherSession{
+		Results: results,
+		b:       b,
+		opts:    opts,
+		tmpl:    tmpl,
+		shots:   make(map[string]tetherShot),
+		done:    make(chan struct{}),
+	}
+
+	go s.run(ctx, results)
+
+	return s, nil
+}
+
+// Err blocks until the session ended and returns why,
+// the context's error or the error that stopped the event stream.
+func (s *TetherSession) Err() error {
+	<-s.done
+	return s.err
+}
+
+func (s *TetherSession) run(ctx context.Context, results chan<- TetherResult) {
+	defer close(s.done)
+	defer close(results)
+
+	events := s.b.Events(ctx, 0)
+	for ev := range events.C {
+		if ev.Type != EventFileAdded {
+			continue
+		}
+
+		res := s.download(ctx, s.file(ev, time.Now()))
+		select {
+		case results <- res:
+		case <-ctx.Done():
+		}
+	}
+
+	s.err = events.Err()
+}
+
+// file numbers the shot ev belongs to.
+func (s *TetherSession) file(ev *CameraEvent, now time.Time) TetherFile {
+	ext := path.Ext(ev.File)
+	f := TetherFile{
+		Folder: ev.Folder,
+		Name:   ev.File,
+		Base:   strings.TrimSuffix(ev.File, ext),
+		Ext:    ext,
+		Time:   now,
+	}
+
+	key := strings.ToLower(path.Join(f.Folder, f.Base))
+	shot, ok := s.shots[key]
+	if !ok || now.Sub(shot.seen) > s.opts.PairWindow {
+		s.seq++
+		shot.seq = s.seq
+	}
+	shot.seen = now
+	s.shots[key] = shot
+
+	for k, sh := range s.shots {
+		if now.Sub(sh.seen) > s.opts.PairWindow {
+			delete(s.shots, k)
+		}
+	}
+
+	f.Seq = shot.seq
+	return f
+}
+
+type contextFileReader interface {
+	FileReaderContext(ctx context.Context, folder, file string, opts ...FileOption) (io.ReadCloser, error)
+}
+
+func (s *TetherSession) download(ctx context.Context, f TetherFile) TetherResult {
+	res := TetherResult{TetherFile: f}
+
+	name := new(bytes.Buffer)
+	if res.Err = s.tmpl.Execute(name, f); res.Err != nil {
+		return res
+	}
+	res.Path = filepath.Join(s.opts.Dir, filepath.FromSlash(name.String()))
+	rel, err := filepath.Rel(filepath.Join(s.opts.Dir, "."), res.Path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		res.Err = errors.New("tether: invalid file name: " + name.String())
+		return res
+	}
+
+	write := func(w io.Writer) (int64, error) {
+		return download(ctx, s.b, f.Folder, f.Name, w)
+	}
+	if res.Path, res.Size, res.Err = writeFileAtomic(res.Path, write); res.Err != nil {
+		return res
+	}
+
+	if s.opts.Delete {
+		res.Err = s.b.DeleteFile(f.Folder, f.Name)
+	}
+
+	return res
+}
+
+// writeFileAtomic writes to a temporary file with write, it is linked to p once complete.
+// If p exists, a suffix is added to it, the returned path is the one written.
+func writeFileAtomic(p string, write func(io.Writer) (int64, error)) (string, int64, error) {
+	dir := filepath.Dir(p)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return p, 0, err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".gphoto2go-*")
+	if err != nil {
+		return p, 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := write(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return p, n, err
+	}
+
+	// Unlike renaming, linking fails if the file exists.
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for i := 1; ; i++ {
+		err = os.Link(tmp.Name(), p)
+		if !errors.Is(err, fs.ErrExist) {
+			return p, n, err
+		}
+		p = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
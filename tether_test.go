@@ -0,0 +1,118 @@
+package gphoto2go
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTetherSession(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetCapture([]byte("shot"), ".CR2", ".JPG")
+
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := StartTether(ctx, f, TetherOptions{
+		Dir:      dir,
+		Template: `shot_{{printf "%03d" .Seq}}{{.Ext}}`,
+		Delete:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.TriggerCapture(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.TriggerCapture(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"shot_001.CR2", "shot_001.JPG", "shot_002.CR2", "shot_002.JPG"}
+	for _, name := range exp {
+		var res TetherResult
+		select {
+		case res = <-s.Results:
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s to be downloaded", name)
+		}
+
+		if res.Err != nil {
+			t.Fatalf("%s: %v", name, res.Err)
+		}
+		if res.Path != filepath.Join(dir, name) || res.Size != 4 {
+			t.Errorf("expected %s of 4 bytes, got %s of %d bytes", name, res.Path, res.Size)
+		}
+		if data, err := os.ReadFile(res.Path); err != nil || string(data) != "shot" {
+			t.Errorf("expected %s to contain the shot, got %q %v", name, data, err)
+		}
+		if _, err := f.Info(res.Folder, res.Name); err == nil {
+			t.Errorf("expected %s to be deleted from the camera", res.Name)
+		}
+	}
+
+	cancel()
+	for range s.Results {
+	}
+	if err := s.Err(); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestTetherInvalidName(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, err := StartTether(ctx, f, TetherOptions{Dir: t.TempDir(), Template: "../{{.Name}}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.TriggerCapture()
+	if res := <-s.Results; res.Err == nil {
+		t.Errorf("expected an error for %s", res.Path)
+	}
+}
+
+func TestWriteFileAtomicExists(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "IMG_0001.JPG")
+	if err := os.WriteFile(p, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"IMG_0001-1.JPG", "IMG_0001-2.JPG"}
+	for i, name := range exp {
+		data := []byte{'0' + byte(i)}
+		got, n, err := writeFileAtomic(p, func(w io.Writer) (int64, error) {
+			n, err := w.Write(data)
+			return int64(n), err
+		})
+		if err != nil || n != 1 || got != filepath.Join(dir, name) {
+			t.Fatalf("expected %s, got %s %d %v", name, got, n, err)
+		}
+		if b, _ := os.ReadFile(got); string(b) != string(data) {
+			t.Errorf("%s: expected %q, got %q", name, data, b)
+		}
+	}
+
+	if b, _ := os.ReadFile(p); string(b) != "old" {
+		t.Errorf("expected the existing file to be kept, got %q", b)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("expected no temporary files to be left, got %d files", len(entries))
+	}
+}
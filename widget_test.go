@@ -0,0 +1,208 @@
+package gphoto2go
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWidgetRange(t *testing.T) {
+	w, err := NewWidget(WidgetRange, "zoom", "Zoom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Free()
+	if err := w.SetRange(1, 10, 0.5); err != nil {
+		t.Fatal(err)
+	}
+
+	min, max, step, err := w.Range()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if min != 1 || max != 10 || step != 0.5 {
+		t.Errorf("expected 1 10 0.5, got %v %v %v", min, max, step)
+	}
+
+	if err := w.SetFloat(2.5); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := w.Value(); err != nil || v != 2.5 {
+		t.Errorf("expected 2.5, got %v %v", v, err)
+	}
+	if err := w.SetValue(4); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := w.Float(); v != 4 {
+		t.Errorf("expected 4, got %v", v)
+	}
+
+	for _, v := range []float64{0.5, 10.5, 2.25} {
+		if err := w.SetFloat(v); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("expected ErrInvalidValue for %v, got %v", v, err)
+		}
+	}
+	if v, _ := w.Float(); v != 4 {
+		t.Errorf("invalid values changed the widget to %v", v)
+	}
+	if err := w.SetString("4"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue for a string, got %v", err)
+	}
+}
+
+func TestWidgetRangeFloat32(t *testing.T) {
+	w, err := NewWidget(WidgetRange, "exposurecompensation", "Exposure Compensation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Free()
+	if err := w.SetRange(0.1, 0.9, 0.1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []float64{0.1, 0.7, 0.9} {
+		if err := w.SetFloat(v); err != nil {
+			t.Errorf("expected %v to be accepted, got %v", v, err)
+		}
+		got, err := w.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.SetValue(got); err != nil {
+			t.Errorf("expected the value %v read back to be accepted, got %v", got, err)
+		}
+	}
+	if err := w.SetFloat(0.9000001); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue above the range, got %v", err)
+	}
+}
+
+func TestWidgetToggle(t *testing.T) {
+	w, err := NewWidget(WidgetToggle, "autofocus", "Autofocus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Free()
+
+	if err := w.SetValue(true); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := w.Value(); err != nil || v != 1 {
+		t.Errorf("expected 1, got %v %v", v, err)
+	}
+	if err := w.SetInt(0); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := w.Int(); v != 0 {
+		t.Errorf("expected 0, got %v", v)
+	}
+	if err := w.SetInt(2); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+	if err := w.SetValue(1.0); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue for a float, got %v", err)
+	}
+}
+
+func TestWidgetDate(t *testing.T) {
+	w, err := NewWidget(WidgetDate, "datetime", "Camera Date and Time")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Free()
+
+	now := time.Unix(time.Now().Unix(), 0)
+	if err := w.SetValue(now); err != nil {
+		t.Fatal(err)
+	}
+	v, err := w.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tm, ok := v.(time.Time); !ok || !tm.Equal(now) {
+		t.Errorf("expected %v, got %v", now, v)
+	}
+
+	if err := w.SetTime(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestWidgetReadonly(t *testing.T) {
+	w, err := NewWidget(WidgetText, "serialnumber", "Serial Number")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Free()
+	if err := w.SetReadonly(true); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.SetValue("1234"); err != ErrReadOnly {
+		t.Errorf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestWidgetChoices(t *testing.T) {
+	for _, typ := range []WidgetType{WidgetRadio, WidgetMenu} {
+		w, err := NewWidget(typ, "whitebalance", "WhiteBalance")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer w.Free()
+
+		expect := []string{"Auto", "Daylight", "Shadow"}
+		for _, c := range expect {
+			if err := w.AddChoice(c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		choices, err := w.Choices()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(choices, expect) {
+			t.Errorf("%d: expected %v, got %v", typ, expect, choices)
+		}
+		if vt, _ := w.ValueType(); vt != "string" {
+			t.Errorf("%d: expected a string value, got %s", typ, vt)
+		}
+
+		if err := w.SetChoice(1); err != nil {
+			t.Fatal(err)
+		}
+		if v, _ := w.Value(); v != "Daylight" {
+			t.Errorf("%d: expected Daylight, got %v", typ, v)
+		}
+		if err := w.SetChoiceValue("Shadow"); err != nil {
+			t.Fatal(err)
+		}
+		if i, err := w.Choice(); err != nil || i != 2 {
+			t.Errorf("%d: expected choice 2, got %d %v", typ, i, err)
+		}
+
+		if err := w.SetChoice(3); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("%d: expected ErrInvalidValue, got %v", typ, err)
+		}
+		if err := w.SetChoiceValue("Tungsten"); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("%d: expected ErrInvalidValue, got %v", typ, err)
+		}
+
+		if err := w.SetString("Unlisted"); err != nil {
+			t.Fatal(err)
+		}
+		if i, err := w.Choice(); err != nil || i != -1 {
+			t.Errorf("%d: expected choice -1, got %d %v", typ, i, err)
+		}
+	}
+
+	w, err := NewWidget(WidgetText, "ownername", "Owner Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Free()
+	if choices, err := w.Choices(); err != nil || len(choices) != 0 {
+		t.Errorf("expected no choices for a text widget, got %v %v", choices, err)
+	}
+}
@@ -0,0 +1,326 @@
+package gphoto2go
+
+// #cgo pkg-config: libgphoto2
+// #include <gphoto2.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// SkipChildren can be returned by the function passed to Walk
+// to skip the children of a widget.
+var SkipChildren = errors.New("skip children")
+
+// Children returns the direct children of the widget in order.
+func (w *CameraWidget) Children() ([]*CameraWidget, error) {
+	n := C.gp_widget_count_children(w.widget)
+	if n < C.GP_OK {
+		return nil, cameraResultToError(n)
+	}
+
+	children := make([]*CameraWidget, n)
+	for i := range children {
+		var child *C.CameraWidget
+		if err := cameraResultToError(C.gp_widget_get_child(w.widget, C.int(i), &child)); err != nil {
+			return nil, err
+		}
+		children[i] = &CameraWidget{child}
+	}
+
+	return children, nil
+}
+
+// Walk calls fn for the widget and all its descendants, depth-first and in order.
+// The path is relative to w, see Path.
+// Returning SkipChildren skips the children of a widget, any other error stops the walk and is returned.
+func (w *CameraWidget) Walk(fn func(path string, w *CameraWidget) error) error {
+	name, err := w.Name()
+	if err != nil {
+		return err
+	}
+
+	err = w.walk("/"+name, fn)
+	if err == SkipChildren {
+		return nil
+	}
+	return err
+}
+
+func (w *CameraWidget) walk(path string, fn func(string, *CameraWidget) error) error {
+	if err := fn(path, w); err != nil {
+		return err
+	}
+
+	children, err := w.Children()
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		name, err := child.Name()
+		if err != nil {
+			return err
+		}
+		if err := child.walk(strings.TrimSuffix(path, "/")+"/"+name, fn); err != nil && err != SkipChildren {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Root returns the top of the tree the widget belongs to.
+func (w *CameraWidget) Root() (*CameraWidget, error) {
+	root := new(CameraWidget)
+	if err := cameraResultToError(C.gp_widget_get_root(w.widget, &root.widget)); err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+// Path returns the names from the root down to the widget, e.g. "/main/capturesettings/shutterspeed".
+// A root without a name, like the one of a Fake, is left out.
+func (w *CameraWidget) Path() (string, error) {
+	var names []string
+	for cur := w; cur != nil && cur.widget != nil; {
+		name, err := cur.Name()
+		if err != nil {
+			return "", err
+		}
+		names = append(names, name)
+
+		if cur, err = cur.Parent(); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	for i := len(names) - 1; i >= 0; i-- {
+		if names[i] == "" && i == len(names)-1 {
+			continue
+		}
+		b.WriteString("/")
+		b.WriteString(names[i])
+	}
+	if b.Len() == 0 {
+		return "/", nil
+	}
+
+	return b.String(), nil
+}
+
+// ChildByPath finds a widget by its path.
+// Absolute paths, e.g. "/main/capturesettings/shutterspeed", start at the root of the tree,
+// relative ones, e.g. "capturesettings/shutterspeed", at w.
+func (w *CameraWidget) ChildByPath(path string) (*CameraWidget, error) {
+	cur := w
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if strings.HasPrefix(path, "/") {
+		root, err := w.Root()
+		if err != nil {
+			return nil, err
+		}
+		name, err := root.Name()
+		if err != nil {
+			return nil, err
+		}
+		cur = root
+		if name != "" {
+			if parts[0] != name {
+				return nil, fmt.Errorf("widget %s: %w", path, newError(ErrBadParameters))
+			}
+			parts = parts[1:]
+		}
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+
+		children, err := cur.Children()
+		if err != nil {
+			return nil, err
+		}
+
+		var next *CameraWidget
+		for _, child := range children {
+			if name, err := child.Name(); err == nil && name == part {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("widget %s: %w", path, newError(ErrBadParameters))
+		}
+		cur = next
+	}
+
+	return cur, nil
+}
+
+// Lookup finds a widget by path if key contains a "/", otherwise by name or else by label,
+// like the gphoto2 command line tool does.
+func (w *CameraWidget) Lookup(key string) (*CameraWidget, error) {
+	if strings.Contains(key, "/") {
+		return w.ChildByPath(key)
+	}
+	if child, err := w.Child(key); err == nil {
+		return child, nil
+	}
+
+	return w.ChildByLabel(key)
+}
+
+// ID returns the unique id of the widget within its tree.
+func (w *CameraWidget) ID() (int, error) {
+	var id C.int
+	if err := cameraResultToError(C.gp_widget_get_id(w.widget, &id)); err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Info returns the help text of the widget.
+func (w *CameraWidget) Info() (string, error) {
+	var info *C.char
+	if err := cameraResultToError(C.gp_widget_get_info(w.widget, &info)); err != nil {
+		return "", err
+	}
+
+	return ToString(info), nil
+}
+
+// SetInfo sets the help text of the widget
+func (w *CameraWidget) SetInfo(info string) error {
+	cInfo := C.CString(info)
+	defer C.free(unsafe.Pointer(cInfo))
+
+	return cameraResultToError(C.gp_widget_set_info(w.widget, cInfo))
+}
+
+// Changed reports whether the value of the widget was set since the configuration was read or written,
+// SetConfig only writes changed widgets.
+func (w *CameraWidget) Changed() (bool, error) {
+	// gp_widget_changed clears the flag, put it back.
+	ret := C.gp_widget_changed(w.widget)
+	if ret < C.GP_OK {
+		return false, cameraResultToError(ret)
+	}
+	if ret == 1 {
+		if err := w.SetChanged(true); err != nil {
+			return false, err
+		}
+	}
+
+	return ret == 1, nil
+}
+
+// SetChanged sets or clears the changed flag of the widget,
+// e.g. to have SetConfig apply a value again.
+func (w *CameraWidget) SetChanged(changed bool) error {
+	c := C.int(0)
+	if changed {
+		c = 1
+	}
+
+	return cameraResultToError(C.gp_widget_set_changed(w.widget, c))
+}
+
+// clone copies the widget and its descendants into a new detached tree without changed flags,
+// like reading the configuration again does. It has to be freed.
+func (w *CameraWidget) clone() (*CameraWidget, error) {
+	typ, err := w.Type()
+	if err != nil {
+		return nil, err
+	}
+	name, err := w.Name()
+	if err != nil {
+		return nil, err
+	}
+	label, err := w.Label()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := NewWidget(typ.Type(), name, label)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.cloneInto(c, typ); err != nil {
+		c.Free()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (w *CameraWidget) cloneInto(c *CameraWidget, typ *WidgetTypeInfo) error {
+	info, err := w.Info()
+	if err != nil {
+		return err
+	}
+	if err := c.SetInfo(info); err != nil {
+		return err
+	}
+	readonly, err := w.Readonly()
+	if err != nil {
+		return err
+	}
+	if err := c.SetReadonly(readonly); err != nil {
+		return err
+	}
+
+	if typ.Type() == WidgetRange {
+		min, max, step, err := w.Range()
+		if err != nil {
+			return err
+		}
+		if err := c.SetRange(min, max, step); err != nil {
+			return err
+		}
+	}
+	choices, err := w.Choices()
+	if err != nil {
+		return err
+	}
+	for _, choice := range choices {
+		if err := c.AddChoice(choice); err != nil {
+			return err
+		}
+	}
+	if typ.vtype != wvtWeird {
+		v, err := w.Value()
+		if err != nil {
+			return err
+		}
+		if err := c.setValue(v); err != nil {
+			return err
+		}
+	}
+	if err := c.SetChanged(false); err != nil {
+		return err
+	}
+
+	children, err := w.Children()
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		cc, err := child.clone()
+		if err != nil {
+			return err
+		}
+		if err := c.Append(cc); err != nil {
+			cc.Free()
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package gphoto2go
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testTree builds /main/capturesettings/{shutterspeed,iso} and /main/settings/ownername.
+func testTree(t *testing.T) *CameraWidget {
+	t.Helper()
+	widget := func(typ WidgetType, name, label string) *CameraWidget {
+		w, err := NewWidget(typ, name, label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+
+	root := widget(WidgetWindow, "main", "Camera and Driver Configuration")
+	capture := widget(WidgetSection, "capturesettings", "Capture Settings")
+	settings := widget(WidgetSection, "settings", "Camera Settings")
+	shutter := widget(WidgetRadio, "shutterspeed", "Shutter Speed")
+	iso := widget(WidgetRadio, "iso", "ISO Speed")
+	owner := widget(WidgetText, "ownername", "Owner Name")
+	if err := shutter.SetInfo("Exposure time"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range [][2]*CameraWidget{
+		{root, capture}, {root, settings}, {capture, shutter}, {capture, iso}, {settings, owner},
+	} {
+		if err := a[0].Append(a[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestWidgetWalk(t *testing.T) {
+	root := testTree(t)
+	defer root.Free()
+
+	var paths []string
+	err := root.Walk(func(path string, w *CameraWidget) error {
+		paths = append(paths, path)
+		if p, err := w.Path(); err != nil || p != path {
+			t.Errorf("expected path %s, got %s %v", path, p, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		"/main",
+		"/main/capturesettings",
+		"/main/capturesettings/shutterspeed",
+		"/main/capturesettings/iso",
+		"/main/settings",
+		"/main/settings/ownername",
+	}
+	if !reflect.DeepEqual(paths, expect) {
+		t.Errorf("expected %v, got %v", expect, paths)
+	}
+
+	paths = paths[:0]
+	err = root.Walk(func(path string, w *CameraWidget) error {
+		paths = append(paths, path)
+		if path == "/main/capturesettings" {
+			return SkipChildren
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 4 {
+		t.Errorf("expected the children of capturesettings to be skipped, got %v", paths)
+	}
+}
+
+func TestWidgetLookup(t *testing.T) {
+	root := testTree(t)
+	defer root.Free()
+
+	for _, key := range []string{
+		"/main/capturesettings/shutterspeed",
+		"capturesettings/shutterspeed",
+		"shutterspeed",
+		"Shutter Speed",
+	} {
+		w, err := root.Lookup(key)
+		if err != nil {
+			t.Errorf("%s: %v", key, err)
+			continue
+		}
+		if name, _ := w.Name(); name != "shutterspeed" {
+			t.Errorf("%s: expected shutterspeed, got %s", key, name)
+		}
+	}
+
+	capture, err := root.Lookup("capturesettings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	children, err := capture.Children()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("expected 2 children, got %d", len(children))
+	}
+	if info, _ := children[0].Info(); info != "Exposure time" {
+		t.Errorf("expected the help text, got %q", info)
+	}
+	if id0, _ := children[0].ID(); id0 == 0 {
+		t.Error("expected an id")
+	} else if id1, _ := children[1].ID(); id1 == id0 {
+		t.Error("expected unique ids")
+	}
+
+	if w, err := capture.ChildByPath("/main/settings/ownername"); err != nil {
+		t.Error(err)
+	} else if p, _ := w.Path(); p != "/main/settings/ownername" {
+		t.Errorf("expected an absolute lookup from the root, got %s", p)
+	}
+	for _, key := range []string{"/main/nope", "/other/settings", "settings/ownername"} {
+		if _, err := capture.ChildByPath(key); !isCode(err, ErrBadParameters) {
+			t.Errorf("%s: expected ErrBadParameters, got %v", key, err)
+		}
+	}
+}
+
+func TestWidgetChanged(t *testing.T) {
+	root := testTree(t)
+	defer root.Free()
+
+	owner, err := root.Lookup("ownername")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := owner.Changed(); err != nil || changed {
+		t.Errorf("expected unchanged, got %v %v", changed, err)
+	}
+	if err := owner.SetValue("someone"); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := owner.Changed(); !changed {
+		t.Error("expected changed")
+	}
+	if changed, _ := owner.Changed(); !changed {
+		t.Error("expected Changed not to clear the flag")
+	}
+	if err := owner.SetChanged(false); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := owner.Changed(); changed {
+		t.Error("expected SetChanged to clear the flag")
+	}
+}
+
+func TestWidgetChangedSetConfig(t *testing.T) {
+	f, err := NewFake()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, _ := f.Config()
+	owner, err := NewWidget(WidgetText, "ownername", "Owner Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := root.Append(owner); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := owner.SetValue("someone"); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := owner.Changed(); !changed {
+		t.Fatal("expected changed")
+	}
+	if err := f.SetConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f.written, []string{"/ownername"}) {
+		t.Errorf("expected SetConfig to write /ownername, got %v", f.written)
+	}
+	if changed, _ := owner.Changed(); changed {
+		t.Error("expected SetConfig to clear the flag")
+	}
+}
+
+func TestWidgetUnnamedRoot(t *testing.T) {
+	root, err := NewWidget(WidgetWindow, "", "Camera and Driver Configuration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Free()
+	settings, err := NewWidget(WidgetSection, "settings", "Camera Settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, err := NewWidget(WidgetText, "ownername", "Owner Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := settings.Append(owner); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.Append(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err := root.Path(); err != nil || p != "/" {
+		t.Errorf("expected /, got %q %v", p, err)
+	}
+	if p, err := owner.Path(); err != nil || p != "/settings/ownername" {
+		t.Errorf("expected /settings/ownername, got %q %v", p, err)
+	}
+
+	var paths []string
+	root.Walk(func(path string, _ *CameraWidget) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if exp := []string{"/", "/settings", "/settings/ownername"}; !reflect.DeepEqual(paths, exp) {
+		t.Errorf("expected %v, got %v", exp, paths)
+	}
+
+	w, err := settings.ChildByPath("/settings/ownername")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := w.Name(); name != "ownername" {
+		t.Errorf("expected ownername, got %s", name)
+	}
+}